@@ -0,0 +1,265 @@
+// Copyright 2023 The Go SSI Framework Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+package diddoc
+
+import (
+	"bytes"
+	"crypto"
+	_ "crypto/sha256"
+	_ "crypto/sha512"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode/utf16"
+)
+
+var (
+	errInvalidNumber       error = errors.New("invalid_number")
+	errDuplicateMember     error = errors.New("duplicate_member")
+	errTrailingData        error = errors.New("trailing_data")
+	errUnsupportedHash     error = errors.New("unsupported_hash")
+	errUnsupportedJSONType error = errors.New("unsupported_json_type")
+
+	// multihashCodes are the multicodec codes of the supported hash functions
+	multihashCodes = map[crypto.Hash]uint64{
+		crypto.SHA256: 0x12,
+		crypto.SHA384: 0x20,
+		crypto.SHA512: 0x13,
+	}
+)
+
+// Canonicalize returns the JSON Canonicalization Scheme (RFC 8785) representation of the document
+func (d *Document) Canonicalize() ([]byte, error) {
+	data, err := d.MarshalJSON()
+	if err != nil {
+		return nil, err
+	}
+	return Canonicalize(data)
+}
+
+// Digest returns the digest of the canonical representation of the document
+func (d *Document) Digest(hash crypto.Hash) ([]byte, error) {
+	data, err := d.Canonicalize()
+	if err != nil {
+		return nil, err
+	}
+	return digest(hash, data)
+}
+
+// Multihash returns the multihash of the canonical representation of the document
+func (d *Document) Multihash(hash crypto.Hash) ([]byte, error) {
+	sum, err := d.Digest(hash)
+	if err != nil {
+		return nil, err
+	}
+	return EncodeMultihash(hash, sum)
+}
+
+// EncodeMultihash prefixes the digest with the multicodec code of the hash function and the digest length
+func EncodeMultihash(hash crypto.Hash, sum []byte) ([]byte, error) {
+	code, ok := multihashCodes[hash]
+	if !ok {
+		return nil, errUnsupportedHash
+	}
+	multihash := binary.AppendUvarint(nil, code)
+	multihash = binary.AppendUvarint(multihash, uint64(len(sum)))
+	return append(multihash, sum...), nil
+}
+
+func digest(hash crypto.Hash, data []byte) ([]byte, error) {
+	if _, ok := multihashCodes[hash]; !ok || !hash.Available() {
+		return nil, errUnsupportedHash
+	}
+	h := hash.New()
+	h.Write(data)
+	return h.Sum(nil), nil
+}
+
+// Canonicalize returns the JSON Canonicalization Scheme (RFC 8785) representation of the JSON data. The data must
+// be a single I-JSON value, so duplicate member names are rejected.
+func Canonicalize(data []byte) ([]byte, error) {
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+	value, err := decodeCanonical(dec)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := dec.Token(); err != io.EOF {
+		return nil, errTrailingData
+	}
+	var buf bytes.Buffer
+	if err := writeCanonical(&buf, value); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// decodeCanonical decodes the next JSON value of the decoder, rejecting the duplicate member names of an object
+func decodeCanonical(dec *json.Decoder) (interface{}, error) {
+	tok, err := dec.Token()
+	if err != nil {
+		return nil, err
+	}
+	switch tok {
+	case json.Delim('['):
+		values := []interface{}{}
+		for dec.More() {
+			value, err := decodeCanonical(dec)
+			if err != nil {
+				return nil, err
+			}
+			values = append(values, value)
+		}
+		if _, err := dec.Token(); err != nil {
+			return nil, err
+		}
+		return values, nil
+	case json.Delim('{'):
+		members := map[string]interface{}{}
+		for dec.More() {
+			key, err := dec.Token()
+			if err != nil {
+				return nil, err
+			}
+			name := key.(string)
+			if _, ok := members[name]; ok {
+				return nil, fmt.Errorf("%w: %q", errDuplicateMember, name)
+			}
+			value, err := decodeCanonical(dec)
+			if err != nil {
+				return nil, err
+			}
+			members[name] = value
+		}
+		if _, err := dec.Token(); err != nil {
+			return nil, err
+		}
+		return members, nil
+	}
+	return tok, nil
+}
+
+func writeCanonical(buf *bytes.Buffer, value interface{}) error {
+	switch v := value.(type) {
+	case nil:
+		buf.WriteString("null")
+	case bool:
+		buf.WriteString(strconv.FormatBool(v))
+	case json.Number:
+		f, err := strconv.ParseFloat(string(v), 64)
+		if err != nil {
+			return errInvalidNumber
+		}
+		number, err := formatNumber(f)
+		if err != nil {
+			return err
+		}
+		buf.WriteString(number)
+	case string:
+		writeCanonicalString(buf, v)
+	case []interface{}:
+		buf.WriteByte('[')
+		for i, item := range v {
+			if i > 0 {
+				buf.WriteByte(',')
+			}
+			if err := writeCanonical(buf, item); err != nil {
+				return err
+			}
+		}
+		buf.WriteByte(']')
+	case map[string]interface{}:
+		keys := make([]string, 0, len(v))
+		for key := range v {
+			keys = append(keys, key)
+		}
+		// the members are sorted by the UTF-16 code units of their names
+		sort.Slice(keys, func(i, j int) bool {
+			return lessUTF16(keys[i], keys[j])
+		})
+		buf.WriteByte('{')
+		for i, key := range keys {
+			if i > 0 {
+				buf.WriteByte(',')
+			}
+			writeCanonicalString(buf, key)
+			buf.WriteByte(':')
+			if err := writeCanonical(buf, v[key]); err != nil {
+				return err
+			}
+		}
+		buf.WriteByte('}')
+	default:
+		return errUnsupportedJSONType
+	}
+	return nil
+}
+
+// formatNumber formats the number as the ECMAScript Number.prototype.toString method does
+func formatNumber(f float64) (string, error) {
+	if math.IsNaN(f) || math.IsInf(f, 0) {
+		return "", errInvalidNumber
+	}
+	if f == 0 {
+		// also for negative zero
+		return "0", nil
+	}
+	abs := math.Abs(f)
+	if abs >= 1e-6 && abs < 1e21 {
+		return strconv.FormatFloat(f, 'f', -1, 64), nil
+	}
+	// ECMAScript has no leading zeros in the exponent, e.g. 1e-7 instead of 1e-07
+	s := strconv.FormatFloat(f, 'e', -1, 64)
+	mantissa, exponent, _ := strings.Cut(s, "e")
+	sign, digits := exponent[:1], strings.TrimLeft(exponent[1:], "0")
+	return mantissa + "e" + sign + digits, nil
+}
+
+func writeCanonicalString(buf *bytes.Buffer, s string) {
+	const hex = "0123456789abcdef"
+	buf.WriteByte('"')
+	for _, r := range s {
+		switch r {
+		case '"':
+			buf.WriteString(`\"`)
+		case '\\':
+			buf.WriteString(`\\`)
+		case '\b':
+			buf.WriteString(`\b`)
+		case '\f':
+			buf.WriteString(`\f`)
+		case '\n':
+			buf.WriteString(`\n`)
+		case '\r':
+			buf.WriteString(`\r`)
+		case '\t':
+			buf.WriteString(`\t`)
+		default:
+			if r < 0x20 {
+				buf.WriteString(`\u00`)
+				buf.WriteByte(hex[r>>4])
+				buf.WriteByte(hex[r&0xf])
+			} else {
+				buf.WriteRune(r)
+			}
+		}
+	}
+	buf.WriteByte('"')
+}
+
+func lessUTF16(a, b string) bool {
+	ua, ub := utf16.Encode([]rune(a)), utf16.Encode([]rune(b))
+	for i := 0; i < len(ua) && i < len(ub); i++ {
+		if ua[i] != ub[i] {
+			return ua[i] < ub[i]
+		}
+	}
+	return len(ua) < len(ub)
+}
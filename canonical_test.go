@@ -0,0 +1,74 @@
+// Copyright 2023 The Go SSI Framework Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+package diddoc_test
+
+import (
+	"crypto"
+	"crypto/sha256"
+	"encoding/json"
+	"testing"
+
+	"github.com/gossif/diddoc"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCanonicalize(t *testing.T) {
+	type errorTestCases struct {
+		description    string
+		input          string
+		expectedOutput string
+		expectedError  bool
+	}
+	for _, scenario := range []errorTestCases{
+		{description: "whitespace", input: "{ \"b\" : [ 1 , true , null ] ,\n \"a\" : { } }", expectedOutput: `{"a":{},"b":[1,true,null]}`},
+		{description: "numbers", input: `[333333333.33333329, 1E30, 4.50, 2e-3, 0.000000000000000000000000001, -0, 1e21, 1e-7, 1e-6, 100]`, expectedOutput: `[333333333.3333333,1e+30,4.5,0.002,1e-27,0,1e+21,1e-7,0.000001,100]`},
+		{description: "strings", input: `{"string":"\u20ac$\u000F\u000aA'\u0042\u0022\u005c\\\"\/"}`, expectedOutput: `{"string":"€$\u000f\nA'B\"\\\\\"/"}`},
+		{description: "utf-16 sort order", input: `{"\u20ac":"Euro Sign","\r":"Carriage Return","\ufb33":"Hebrew Letter Dalet With Dagesh","1":"One","\ud83d\ude00":"Emoji: Grinning Face","\u0080":"Control","\u00f6":"Latin Small Letter O With Diaeresis"}`, expectedOutput: "{\"\\r\":\"Carriage Return\",\"1\":\"One\",\"\u0080\":\"Control\",\"\u00f6\":\"Latin Small Letter O With Diaeresis\",\"\u20ac\":\"Euro Sign\",\"\U0001f600\":\"Emoji: Grinning Face\",\"\ufb33\":\"Hebrew Letter Dalet With Dagesh\"}"},
+		{description: "invalid json", input: `{"a":`, expectedError: true},
+		{description: "number out of range", input: `[1e400]`, expectedError: true},
+		{description: "trailing value", input: `{"a":1}{"b":2}`, expectedError: true},
+		{description: "duplicate member", input: `{"a":1,"b":{"c":1,"c":2}}`, expectedError: true},
+	} {
+		t.Run(scenario.description, func(t *testing.T) {
+			output, err := diddoc.Canonicalize([]byte(scenario.input))
+			if scenario.expectedError {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, scenario.expectedOutput, string(output))
+		})
+	}
+}
+
+func TestDocumentCanonicalize(t *testing.T) {
+	first, second := diddoc.NewDocument(), diddoc.NewDocument()
+	require.NoError(t, json.Unmarshal([]byte(`{"@context":"https://www.w3.org/ns/did/v1","id":"did:example:123","alsoKnownAs":["https://example.com/user"]}`), first))
+	require.NoError(t, json.Unmarshal([]byte(`{"alsoKnownAs":["https://example.com/user"],"id":"did:example:123","@context":"https://www.w3.org/ns/did/v1"}`), second))
+
+	canonical, err := first.Canonicalize()
+	require.NoError(t, err)
+	assert.Equal(t, `{"@context":"https://www.w3.org/ns/did/v1","alsoKnownAs":["https://example.com/user"],"id":"did:example:123"}`, string(canonical))
+
+	t.Run("property order", func(t *testing.T) {
+		other, err := second.Canonicalize()
+		require.NoError(t, err)
+		assert.Equal(t, canonical, other)
+	})
+	t.Run("digest", func(t *testing.T) {
+		digest, err := first.Digest(crypto.SHA256)
+		require.NoError(t, err)
+		expected := sha256.Sum256(canonical)
+		assert.Equal(t, expected[:], digest)
+
+		multihash, err := first.Multihash(crypto.SHA256)
+		require.NoError(t, err)
+		assert.Equal(t, append([]byte{0x12, 0x20}, expected[:]...), multihash)
+	})
+	t.Run("unsupported hash", func(t *testing.T) {
+		_, err := first.Multihash(crypto.MD5)
+		assert.Error(t, err)
+	})
+}
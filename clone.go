@@ -0,0 +1,109 @@
+// Copyright 2023 The Go SSI Framework Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+package diddoc
+
+import (
+	"sync"
+)
+
+// Clone returns a deep copy of the document and its metadata, which can be changed independently of
+// the document. The copy of a snapshot is not read-only.
+func (d *Document) Clone() *Document {
+	defer d.rlock()()
+	return d.clone()
+}
+
+// Snapshot returns a read-only deep copy of the document, the snapshot rejects all mutations with an error,
+// so it can be shared across goroutines, e.g. by a resolver cache
+func (d *Document) Snapshot() *Document {
+	defer d.rlock()()
+	snapshot := d.clone()
+	snapshot.readOnly = true
+	return snapshot
+}
+
+func (d *Document) clone() *Document {
+	properties := make(MapSlice, len(d.properties))
+	for i, prop := range d.properties {
+		properties[i] = MapItem{Key: prop.Key, Value: copyValue(prop.Value)}
+	}
+	var scalars map[string]bool
+	if d.scalars != nil {
+		scalars = make(map[string]bool, len(d.scalars))
+		for key := range d.scalars {
+			scalars[key] = true
+		}
+	}
+	return &Document{
+		mu:         &sync.RWMutex{},
+		properties: properties,
+		metadata:   d.metadata.clone(),
+		scalars:    scalars,
+	}
+}
+
+func (m DocumentMetadata) clone() DocumentMetadata {
+	if m.EquivalentId != nil {
+		m.EquivalentId = append([]string(nil), m.EquivalentId...)
+	}
+	return m
+}
+
+// copyValue returns a deep copy of a property value, the values of other types than those of the
+// builder and the JSON decoder are considered immutable and shared
+func copyValue(v interface{}) interface{} {
+	switch value := v.(type) {
+	case map[string]interface{}:
+		copied := make(map[string]interface{}, len(value))
+		for k, item := range value {
+			copied[k] = copyValue(item)
+		}
+		return copied
+	case []interface{}:
+		copied := make([]interface{}, len(value))
+		for i, item := range value {
+			copied[i] = copyValue(item)
+		}
+		return copied
+	case []string:
+		return append([]string(nil), value...)
+	case VerificationMethod:
+		return value.clone()
+	case []VerificationMethod:
+		copied := make([]VerificationMethod, len(value))
+		for i, item := range value {
+			copied[i] = item.clone()
+		}
+		return copied
+	case []VerificationRelation:
+		copied := make([]VerificationRelation, len(value))
+		for i, item := range value {
+			copied[i] = copyValue(item)
+		}
+		return copied
+	case Service:
+		return value.clone()
+	case []Service:
+		copied := make([]Service, len(value))
+		for i, item := range value {
+			copied[i] = item.clone()
+		}
+		return copied
+	}
+	return v
+}
+
+func (v VerificationMethod) clone() VerificationMethod {
+	if v.PubicKeyJWK != nil {
+		if key, err := v.PubicKeyJWK.Clone(); err == nil {
+			v.PubicKeyJWK = key
+		}
+	}
+	return v
+}
+
+func (s Service) clone() Service {
+	s.ServiceEndpoint = ServiceEndpoint{value: copyValue(s.ServiceEndpoint.value)}
+	return s
+}
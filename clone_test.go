@@ -0,0 +1,75 @@
+// Copyright 2023 The Go SSI Framework Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+package diddoc_test
+
+import (
+	"encoding/json"
+	"sync"
+	"testing"
+
+	"github.com/gossif/diddoc"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestClone(t *testing.T) {
+	doc := diddoc.NewDocument()
+	require.NoError(t, json.Unmarshal([]byte(mutationDocument), doc))
+	require.NoError(t, doc.SetMetadata(diddoc.DocumentMetadata{VersionId: "1", EquivalentId: []string{"did:example:456"}}))
+
+	clone := doc.Clone()
+	assert.Equal(t, doc.Keys(), clone.Keys())
+	assert.Equal(t, doc.Metadata(), clone.Metadata())
+
+	require.NoError(t, clone.RemoveVerificationMethod("#key-1"))
+	clone.Context().([]string)[0] = "https://example.com/context"
+	clone.Metadata().EquivalentId[0] = "did:example:789"
+
+	_, err := doc.GetVerificationMethodById("#key-1")
+	assert.NoError(t, err)
+	assert.Equal(t, []string{diddoc.ContextDIDv1}, doc.Context())
+	assert.Equal(t, []string{"did:example:456"}, doc.Metadata().EquivalentId)
+}
+
+func TestSnapshot(t *testing.T) {
+	doc := diddoc.NewDocument()
+	require.NoError(t, json.Unmarshal([]byte(mutationDocument), doc))
+
+	snapshot := doc.Snapshot()
+	assert.True(t, snapshot.ReadOnly())
+	assert.False(t, doc.ReadOnly())
+
+	assert.ErrorContains(t, snapshot.Set("alsoKnownAs", []string{"https://example.com"}), "read_only_document")
+	assert.ErrorContains(t, snapshot.Delete("service"), "read_only_document")
+	assert.ErrorContains(t, snapshot.RemoveVerificationMethod("#key-1"), "read_only_document")
+	assert.ErrorContains(t, snapshot.SetMetadata(diddoc.DocumentMetadata{}), "read_only_document")
+	assert.ErrorContains(t, json.Unmarshal([]byte(mutationDocument), snapshot), "read_only_document")
+
+	// the values of a snapshot are copies
+	snapshot.Context().([]string)[0] = "https://example.com/context"
+	assert.Equal(t, []string{diddoc.ContextDIDv1}, snapshot.Context())
+	snapshot.Services().([]diddoc.Service)[0].Id = "#other"
+	services, ok := snapshot.Services().([]diddoc.Service)
+	require.True(t, ok)
+	assert.Equal(t, "#linked-domain", services[0].Id)
+
+	// a clone of the snapshot can be changed again
+	assert.NoError(t, snapshot.Clone().Delete("service"))
+
+	// the snapshot is shared across goroutines, while the original document is still being changed
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_, err := snapshot.GetAssociatedVerificationMethod(diddoc.Authentication)
+			assert.NoError(t, err)
+			_, err = json.Marshal(snapshot)
+			assert.NoError(t, err)
+			assert.NoError(t, doc.Set("alsoKnownAs", []string{"https://example.com"}))
+			_ = doc.Get("alsoKnownAs")
+		}()
+	}
+	wg.Wait()
+}
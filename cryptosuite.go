@@ -0,0 +1,168 @@
+// Copyright 2023 The Go SSI Framework Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+package diddoc
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rand"
+	"encoding/asn1"
+	"encoding/json"
+	"errors"
+	"math/big"
+
+	"github.com/piprate/json-gold/ld"
+)
+
+const (
+	// EdDSAJCS2022 is the Data Integrity cryptosuite of Ed25519 signatures over JCS canonicalized documents
+	EdDSAJCS2022 string = "eddsa-jcs-2022"
+	// EdDSARDFC2022 is the Data Integrity cryptosuite of Ed25519 signatures over RDF canonicalized documents
+	EdDSARDFC2022 string = "eddsa-rdfc-2022"
+	// ECDSARDFC2019 is the Data Integrity cryptosuite of P-256 and P-384 signatures over RDF canonicalized documents
+	ECDSARDFC2019 string = "ecdsa-rdfc-2019"
+)
+
+var (
+	errUnsupportedCryptosuite error = errors.New("unsupported_cryptosuite")
+	errKeyTypeMismatch        error = errors.New("key_type_mismatch")
+	errInvalidSignature       error = errors.New("invalid_signature")
+
+	cryptosuites = map[string]cryptosuite{
+		EdDSAJCS2022:  {keyHash: eddsaHash},
+		EdDSARDFC2022: {rdfc: true, keyHash: eddsaHash},
+		ECDSARDFC2019: {rdfc: true, keyHash: ecdsaHash},
+	}
+)
+
+// cryptosuite is a Data Integrity cryptosuite, which is a canonicalization algorithm and a signature algorithm
+type cryptosuite struct {
+	// rdfc is set when the documents are canonicalized with RDF Dataset Canonicalization, otherwise with JCS
+	rdfc bool
+	// keyHash returns the hash function of the key, or an error when the key is not supported by the cryptosuite
+	keyHash func(key crypto.PublicKey) (crypto.Hash, error)
+}
+
+// hashData is the hash of the canonical proof configuration followed by the hash of the canonical document
+func (c cryptosuite) hashData(document, proofConfig []byte, hash crypto.Hash, loader ld.DocumentLoader) ([]byte, error) {
+	canonicalProofConfig, err := c.canonicalize(proofConfig, loader)
+	if err != nil {
+		return nil, err
+	}
+	canonicalDocument, err := c.canonicalize(document, loader)
+	if err != nil {
+		return nil, err
+	}
+	proofConfigHash, err := digest(hash, canonicalProofConfig)
+	if err != nil {
+		return nil, err
+	}
+	documentHash, err := digest(hash, canonicalDocument)
+	if err != nil {
+		return nil, err
+	}
+	return append(proofConfigHash, documentHash...), nil
+}
+
+func (c cryptosuite) canonicalize(data []byte, loader ld.DocumentLoader) ([]byte, error) {
+	if !c.rdfc {
+		return Canonicalize(data)
+	}
+	var input interface{}
+	if err := json.Unmarshal(data, &input); err != nil {
+		return nil, err
+	}
+	options := ld.NewJsonLdOptions("")
+	options.Format = "application/n-quads"
+	options.Algorithm = ld.AlgorithmURDNA2015
+	// undefined terms are an error, instead of being dropped from the signed data
+	options.SafeMode = true
+	options.DocumentLoader = loader
+	normalized, err := ld.NewJsonLdProcessor().Normalize(input, options)
+	if err != nil {
+		return nil, err
+	}
+	nquads, ok := normalized.(string)
+	if !ok {
+		return nil, errInvalidType
+	}
+	return []byte(nquads), nil
+}
+
+// sign signs the hash data, ECDSA signatures are returned in the IEEE P1363 format (r || s)
+func (c cryptosuite) sign(signer crypto.Signer, hash crypto.Hash, hashData []byte) ([]byte, error) {
+	switch key := signer.Public().(type) {
+	case ed25519.PublicKey:
+		return signer.Sign(rand.Reader, hashData, crypto.Hash(0))
+	case *ecdsa.PublicKey:
+		sum, err := digest(hash, hashData)
+		if err != nil {
+			return nil, err
+		}
+		der, err := signer.Sign(rand.Reader, sum, hash)
+		if err != nil {
+			return nil, err
+		}
+		var signature struct{ R, S *big.Int }
+		if _, err := asn1.Unmarshal(der, &signature); err != nil {
+			return nil, err
+		}
+		size := curveSize(key.Curve)
+		p1363 := make([]byte, 2*size)
+		signature.R.FillBytes(p1363[:size])
+		signature.S.FillBytes(p1363[size:])
+		return p1363, nil
+	}
+	return nil, errKeyTypeMismatch
+}
+
+func (c cryptosuite) verify(key crypto.PublicKey, hash crypto.Hash, hashData, signature []byte) error {
+	switch k := key.(type) {
+	case ed25519.PublicKey:
+		if !ed25519.Verify(k, hashData, signature) {
+			return errInvalidSignature
+		}
+		return nil
+	case *ecdsa.PublicKey:
+		size := curveSize(k.Curve)
+		if len(signature) != 2*size {
+			return errInvalidSignature
+		}
+		sum, err := digest(hash, hashData)
+		if err != nil {
+			return err
+		}
+		r, s := new(big.Int).SetBytes(signature[:size]), new(big.Int).SetBytes(signature[size:])
+		if !ecdsa.Verify(k, sum, r, s) {
+			return errInvalidSignature
+		}
+		return nil
+	}
+	return errKeyTypeMismatch
+}
+
+func eddsaHash(key crypto.PublicKey) (crypto.Hash, error) {
+	if _, ok := key.(ed25519.PublicKey); ok {
+		return crypto.SHA256, nil
+	}
+	return 0, errKeyTypeMismatch
+}
+
+func ecdsaHash(key crypto.PublicKey) (crypto.Hash, error) {
+	if k, ok := key.(*ecdsa.PublicKey); ok {
+		switch k.Curve {
+		case elliptic.P256():
+			return crypto.SHA256, nil
+		case elliptic.P384():
+			return crypto.SHA384, nil
+		}
+	}
+	return 0, errKeyTypeMismatch
+}
+
+func curveSize(curve elliptic.Curve) int {
+	return (curve.Params().BitSize + 7) / 8
+}
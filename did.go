@@ -0,0 +1,141 @@
+// Copyright 2023 The Go SSI Framework Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+package diddoc
+
+import (
+	"errors"
+	"net/url"
+	"strings"
+)
+
+const didScheme string = "did"
+
+var (
+	errInvalidDID error = errors.New("invalid_did")
+)
+
+// DID is a decentralized identifier as defined in DID Core 3.1 DID Syntax.
+type DID struct {
+	// Method is the DID method name, e.g. "example" for did:example:123
+	Method string
+	// ID is the method-specific identifier, kept in its (percent-encoded) source form
+	ID string
+	// Segments are the colon separated parts of the method-specific identifier
+	Segments []string
+}
+
+// ParseDID parses a string into a DID and validates it against the DID syntax:
+//
+//	did                = "did:" method-name ":" method-specific-id
+//	method-name        = 1*method-char
+//	method-char        = %x61-7A / DIGIT
+//	method-specific-id = *( *idchar ":" ) 1*idchar
+//	idchar             = ALPHA / DIGIT / "." / "-" / "_" / pct-encoded
+func ParseDID(s string) (DID, error) {
+	parts := strings.SplitN(s, ":", 3)
+	if len(parts) != 3 || parts[0] != didScheme {
+		return DID{}, errInvalidDID
+	}
+	if !isMethodName(parts[1]) {
+		return DID{}, errInvalidDID
+	}
+	segments := strings.Split(parts[2], ":")
+	// only the last segment must be non-empty
+	if segments[len(segments)-1] == "" {
+		return DID{}, errInvalidDID
+	}
+	for _, segment := range segments {
+		if !isIdString(segment) {
+			return DID{}, errInvalidDID
+		}
+	}
+	return DID{
+		Method:   parts[1],
+		ID:       parts[2],
+		Segments: segments,
+	}, nil
+}
+
+// MustParseDID is like ParseDID but panics if the string cannot be parsed.
+func MustParseDID(s string) DID {
+	did, err := ParseDID(s)
+	if err != nil {
+		panic(err)
+	}
+	return did
+}
+
+// IsDID reports whether the string conforms to the DID syntax
+func IsDID(s string) bool {
+	_, err := ParseDID(s)
+	return err == nil
+}
+
+// String returns the DID in its string representation
+func (d DID) String() string {
+	if d.Method == "" {
+		return ""
+	}
+	return didScheme + ":" + d.Method + ":" + d.ID
+}
+
+// IsZero reports whether the DID is empty
+func (d DID) IsZero() bool {
+	return d.Method == "" && d.ID == ""
+}
+
+// DecodedSegments returns the segments of the method-specific identifier with percent-encoding removed
+func (d DID) DecodedSegments() ([]string, error) {
+	decoded := make([]string, 0, len(d.Segments))
+	for _, segment := range d.Segments {
+		value, err := url.PathUnescape(segment)
+		if err != nil {
+			return nil, errInvalidDID
+		}
+		decoded = append(decoded, value)
+	}
+	return decoded, nil
+}
+
+func isMethodName(s string) bool {
+	if s == "" {
+		return false
+	}
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		if !(c >= 'a' && c <= 'z') && !isDigit(c) {
+			return false
+		}
+	}
+	return true
+}
+
+func isIdString(s string) bool {
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		switch {
+		case isAlpha(c), isDigit(c), c == '.', c == '-', c == '_':
+		case c == '%':
+			if i+2 >= len(s) || !isHexDigit(s[i+1]) || !isHexDigit(s[i+2]) {
+				return false
+			}
+			i += 2
+		default:
+			return false
+		}
+	}
+	return true
+}
+
+func isAlpha(c byte) bool {
+	return (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z')
+}
+
+func isDigit(c byte) bool {
+	return c >= '0' && c <= '9'
+}
+
+func isHexDigit(c byte) bool {
+	return isDigit(c) || (c >= 'a' && c <= 'f') || (c >= 'A' && c <= 'F')
+}
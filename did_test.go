@@ -0,0 +1,60 @@
+// Copyright 2023 The Go SSI Framework Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+package diddoc_test
+
+import (
+	"testing"
+
+	"github.com/gossif/diddoc"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseDID(t *testing.T) {
+	type errorTestCases struct {
+		description      string
+		input            string
+		expectedMethod   string
+		expectedId       string
+		expectedSegments []string
+		expectedError    string
+	}
+	for _, scenario := range []errorTestCases{
+		{description: "simple", input: "did:example:123456789abcdefghi", expectedMethod: "example", expectedId: "123456789abcdefghi", expectedSegments: []string{"123456789abcdefghi"}},
+		{description: "segments", input: "did:web:example.com:user:alice", expectedMethod: "web", expectedId: "example.com:user:alice", expectedSegments: []string{"example.com", "user", "alice"}},
+		{description: "percent-encoded", input: "did:web:example.com%3A8443", expectedMethod: "web", expectedId: "example.com%3A8443", expectedSegments: []string{"example.com%3A8443"}},
+		{description: "empty inner segment", input: "did:example::123", expectedMethod: "example", expectedId: ":123", expectedSegments: []string{"", "123"}},
+		{description: "no scheme", input: "example:123", expectedError: "invalid_did"},
+		{description: "uppercase method", input: "did:Example:123", expectedError: "invalid_did"},
+		{description: "empty method", input: "did::123", expectedError: "invalid_did"},
+		{description: "empty id", input: "did:example:", expectedError: "invalid_did"},
+		{description: "trailing colon", input: "did:example:123:", expectedError: "invalid_did"},
+		{description: "invalid char", input: "did:example:12 3", expectedError: "invalid_did"},
+		{description: "fragment", input: "did:example:123#key-1", expectedError: "invalid_did"},
+		{description: "bad percent-encoding", input: "did:example:12%G3", expectedError: "invalid_did"},
+		{description: "truncated percent-encoding", input: "did:example:123%4", expectedError: "invalid_did"},
+	} {
+		t.Run(scenario.description, func(t *testing.T) {
+
+			did, err := diddoc.ParseDID(scenario.input)
+			if scenario.expectedError != "" {
+				assert.ErrorContains(t, err, scenario.expectedError)
+				assert.True(t, did.IsZero())
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, scenario.expectedMethod, did.Method)
+			assert.Equal(t, scenario.expectedId, did.ID)
+			assert.Equal(t, scenario.expectedSegments, did.Segments)
+			assert.Equal(t, scenario.input, did.String())
+		})
+	}
+}
+
+func TestDecodedSegments(t *testing.T) {
+	did := diddoc.MustParseDID("did:web:example.com%3A8443:user:alice")
+
+	segments, err := did.DecodedSegments()
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"example.com:8443", "user", "alice"}, segments)
+}
@@ -0,0 +1,115 @@
+// Copyright 2023 The Go SSI Framework Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package didjwk implements the did:jwk method, which encodes a JWK in the DID itself.
+package didjwk
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/gossif/diddoc"
+	"github.com/gossif/diddoc/internal/jwkutil"
+	"github.com/lestrrat-go/jwx/v2/jwa"
+	"github.com/lestrrat-go/jwx/v2/jwk"
+)
+
+// Method is the DID method name
+const Method string = "jwk"
+
+const (
+	// verificationMethodFragment is the fragment of the single verification method
+	verificationMethodFragment string = "0"
+
+	useSignature  string = "sig"
+	useEncryption string = "enc"
+)
+
+var (
+	errPrivateKey    error = errors.New("private_key_not_allowed")
+	errNotAsymmetric error = errors.New("asymmetric_key_required")
+)
+
+// New creates a did:jwk from the JWK, the private key members are removed from a private key. The key must be an
+// asymmetric key, as the public key of a symmetric key is the secret itself.
+func New(key jwk.Key) (diddoc.DID, error) {
+	switch key.KeyType() {
+	case jwa.EC, jwa.OKP, jwa.RSA:
+	default:
+		return diddoc.DID{}, fmt.Errorf("%w: %s", errNotAsymmetric, key.KeyType())
+	}
+	pubKey, err := key.PublicKey()
+	if err != nil {
+		return diddoc.DID{}, err
+	}
+	data, err := json.Marshal(pubKey)
+	if err != nil {
+		return diddoc.DID{}, err
+	}
+	return diddoc.ParseDID("did:" + Method + ":" + base64.RawURLEncoding.EncodeToString(data))
+}
+
+// Expand expands the did:jwk into a DID document with a single JsonWebKey2020 verification method
+func Expand(did string) (*diddoc.Document, error) {
+	parsed, err := diddoc.ParseDID(did)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", diddoc.InvalidDid, err)
+	}
+	if parsed.Method != Method {
+		return nil, fmt.Errorf("%w: method %q is not %q", diddoc.InvalidDid, parsed.Method, Method)
+	}
+	data, err := base64.RawURLEncoding.DecodeString(parsed.ID)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", diddoc.InvalidDid, err)
+	}
+	key, err := jwk.ParseKey(data)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", diddoc.InvalidDid, err)
+	}
+	if jwkutil.IsPrivate(key) {
+		return nil, fmt.Errorf("%w: %v", diddoc.InvalidDid, errPrivateKey)
+	}
+	verificationMethod := diddoc.VerificationMethod{
+		Id:          did + "#" + verificationMethodFragment,
+		Type:        diddoc.JsonWebKey2020Type,
+		Controller:  did,
+		PubicKeyJWK: key,
+	}
+	b := diddoc.NewBuilder().
+		Context([]string{diddoc.ContextDIDv1, diddoc.ContextJWS2020V1}).
+		Subject(did).
+		VerificationMethod(verificationMethod)
+
+	use := key.KeyUsage()
+	if use != useEncryption {
+		b.AssertionMethod(verificationMethod.Id).
+			Authentication(verificationMethod.Id).
+			CapabilityInvocation(verificationMethod.Id).
+			CapabilityDelegation(verificationMethod.Id)
+	}
+	if use != useSignature {
+		b.KeyAgreement(verificationMethod.Id)
+	}
+	return b.Build()
+}
+
+// Resolver resolves did:jwk DIDs, it can be registered with a diddoc.Registry
+type Resolver struct{}
+
+// NewResolver creates a did:jwk resolver
+func NewResolver() *Resolver {
+	return &Resolver{}
+}
+
+// Resolve expands the did:jwk into a DID document, no network access is required
+func (r *Resolver) Resolve(ctx context.Context, did string, opts diddoc.ResolutionOptions) (*diddoc.Document, diddoc.ResolutionMetadata, diddoc.DocumentMetadata, error) {
+	doc, err := Expand(did)
+	if err != nil {
+		return nil, diddoc.ResolutionMetadata{Error: diddoc.InvalidDid}, diddoc.DocumentMetadata{}, err
+	}
+	return doc, diddoc.ResolutionMetadata{}, diddoc.DocumentMetadata{}, nil
+}
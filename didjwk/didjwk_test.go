@@ -0,0 +1,94 @@
+// Copyright 2023 The Go SSI Framework Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+package didjwk_test
+
+import (
+	"context"
+	"crypto"
+	"encoding/base64"
+	"testing"
+
+	"github.com/gossif/diddoc"
+	"github.com/gossif/diddoc/didjwk"
+	"github.com/lestrrat-go/jwx/v2/jwk"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func didFromJSON(s string) string {
+	return "did:jwk:" + base64.RawURLEncoding.EncodeToString([]byte(s))
+}
+
+func TestExpand(t *testing.T) {
+	type errorTestCases struct {
+		description       string
+		input             string
+		expectedSigning   bool
+		expectedAgreement bool
+		expectedError     string
+	}
+	for _, scenario := range []errorTestCases{
+		{description: "no use", input: didFromJSON(`{"crv":"P-256","kty":"EC","x":"acbIQiuMs3i8_uszEjJ2tpTtRM4EU3yz91PH6CdH2V0","y":"_KcyLj9vWMptnmKtm46GqDz8wf74I5LKgrl2GzH3nSE"}`), expectedSigning: true, expectedAgreement: true},
+		{description: "use sig", input: didFromJSON(`{"crv":"Ed25519","kty":"OKP","use":"sig","x":"Lm_M42cB3HkUiODQsXRcweM6TByfzEHGO9ND274JcOY"}`), expectedSigning: true, expectedAgreement: false},
+		{description: "use enc", input: didFromJSON(`{"kty":"OKP","crv":"X25519","use":"enc","x":"3p7bfXt9wbTTW2HC7OQ1Nz-DQ8hbeGdNrfx-FG-IK08"}`), expectedSigning: false, expectedAgreement: true},
+		{description: "private key", input: didFromJSON(`{"kty":"OKP","crv":"Ed25519","x":"Lm_M42cB3HkUiODQsXRcweM6TByfzEHGO9ND274JcOY","d":"ecWbCiPdNgdR_vZYTsWhU8Kr7xcKGZLQfsa5dTUt7Ts"}`), expectedError: "private_key_not_allowed"},
+		{description: "not base64url", input: "did:jwk:e30.", expectedError: "invalidDid"},
+		{description: "not a jwk", input: didFromJSON(`{"foo":"bar"}`), expectedError: "invalidDid"},
+		{description: "other method", input: "did:key:z6MkhaXgBZDvotDkL5257faiztiGiC2QtKLGpbnnEGta2doK", expectedError: "invalidDid"},
+	} {
+		t.Run(scenario.description, func(t *testing.T) {
+
+			doc, err := didjwk.Expand(scenario.input)
+			if scenario.expectedError != "" {
+				assert.ErrorContains(t, err, scenario.expectedError)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, scenario.input, doc.Subject().String())
+
+			verificationMethod, err := doc.GetVerificationMethodById("#0")
+			require.NoError(t, err)
+			assert.Equal(t, diddoc.JsonWebKey2020Type, verificationMethod.Type)
+			assert.Equal(t, scenario.input, verificationMethod.Controller)
+			assert.Implements(t, (*jwk.Key)(nil), verificationMethod.PubicKeyJWK)
+
+			for _, purpose := range []diddoc.ProofPurpose{diddoc.Authentication, diddoc.AssertionMethod, diddoc.CapabilityInvocation, diddoc.CapabilityDelegation} {
+				_, err := doc.GetAssociatedVerificationMethod(purpose)
+				assert.Equal(t, scenario.expectedSigning, err == nil, purpose)
+			}
+			_, err = doc.GetAssociatedVerificationMethod(diddoc.KeyAgreement)
+			assert.Equal(t, scenario.expectedAgreement, err == nil)
+		})
+	}
+}
+
+func TestNew(t *testing.T) {
+	privKey, err := jwk.ParseKey([]byte(`{"kty":"OKP","crv":"Ed25519","use":"sig","x":"Lm_M42cB3HkUiODQsXRcweM6TByfzEHGO9ND274JcOY","d":"ecWbCiPdNgdR_vZYTsWhU8Kr7xcKGZLQfsa5dTUt7Ts"}`))
+	require.NoError(t, err)
+
+	did, err := didjwk.New(privKey)
+	require.NoError(t, err)
+	assert.Equal(t, didjwk.Method, did.Method)
+
+	doc, _, _, err := didjwk.NewResolver().Resolve(context.Background(), did.String(), diddoc.ResolutionOptions{})
+	require.NoError(t, err)
+
+	verificationMethod, err := doc.GetVerificationMethodById(did.String() + "#0")
+	require.NoError(t, err)
+	pubKey, err := privKey.PublicKey()
+	require.NoError(t, err)
+	expectedThumbprint, err := pubKey.Thumbprint(crypto.SHA256)
+	require.NoError(t, err)
+	actualThumbprint, err := verificationMethod.PubicKeyJWK.(jwk.Key).Thumbprint(crypto.SHA256)
+	require.NoError(t, err)
+	assert.Equal(t, expectedThumbprint, actualThumbprint)
+}
+
+func TestNewSymmetricKey(t *testing.T) {
+	key, err := jwk.ParseKey([]byte(`{"kty":"oct","k":"GawgguFyGrWKav7AX4VKUg"}`))
+	require.NoError(t, err)
+
+	_, err = didjwk.New(key)
+	assert.ErrorContains(t, err, "asymmetric_key_required")
+}
@@ -0,0 +1,175 @@
+// Copyright 2023 The Go SSI Framework Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package didkey implements the did:key method, which expands a public key into a DID document.
+package didkey
+
+import (
+	"context"
+	"crypto"
+	"crypto/ed25519"
+	"fmt"
+
+	"github.com/gossif/diddoc"
+	"github.com/gossif/diddoc/internal/jwkutil"
+	"github.com/gossif/diddoc/multikey"
+	"github.com/lestrrat-go/jwx/v2/jwk"
+)
+
+// Method is the DID method name
+const Method string = "key"
+
+type options struct {
+	jsonWebKey bool
+}
+
+// Option configures the expansion of a did:key into a DID document
+type Option func(*options)
+
+// WithJsonWebKey2020 expands the keys into JsonWebKey2020 verification methods instead of Multikey
+func WithJsonWebKey2020() Option {
+	return func(o *options) {
+		o.jsonWebKey = true
+	}
+}
+
+// New creates a did:key from a JWK, a public key or a multibase encoded key
+func New(key interface{}) (diddoc.DID, error) {
+	var pubKey crypto.PublicKey
+	switch k := key.(type) {
+	case string:
+		codec, data, err := multikey.Decode(k)
+		if err != nil {
+			return diddoc.DID{}, err
+		}
+		if pubKey, err = multikey.UnmarshalPublicKey(codec, data); err != nil {
+			return diddoc.DID{}, err
+		}
+	case jwk.Key:
+		var err error
+		if pubKey, err = jwkutil.PublicKey(k); err != nil {
+			return diddoc.DID{}, err
+		}
+	default:
+		pubKey = key
+	}
+	fingerprint, err := multikey.EncodePublicKey(pubKey)
+	if err != nil {
+		return diddoc.DID{}, err
+	}
+	return diddoc.ParseDID("did:" + Method + ":" + fingerprint)
+}
+
+// Expand expands the did:key into a DID document
+func Expand(did string, opts ...Option) (*diddoc.Document, error) {
+	parsed, err := diddoc.ParseDID(did)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", diddoc.InvalidDid, err)
+	}
+	if parsed.Method != Method {
+		return nil, fmt.Errorf("%w: method %q is not %q", diddoc.InvalidDid, parsed.Method, Method)
+	}
+	return ExpandFingerprint(did, parsed.ID, opts...)
+}
+
+// ExpandFingerprint expands the multibase encoded key into a DID document of the DID, this allows
+// methods that are derived from did:key, such as did:peer:0, to share the expansion rules
+func ExpandFingerprint(did string, fingerprint string, opts ...Option) (*diddoc.Document, error) {
+	o := options{}
+	for _, opt := range opts {
+		opt(&o)
+	}
+	codec, data, err := multikey.Decode(fingerprint)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", diddoc.InvalidDid, err)
+	}
+	pubKey, err := multikey.UnmarshalPublicKey(codec, data)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", diddoc.InvalidDid, err)
+	}
+	verificationMethod, err := newVerificationMethod(did, fingerprint, pubKey, o)
+	if err != nil {
+		return nil, err
+	}
+	contexts := []string{diddoc.ContextDIDv1, diddoc.ContextMultikeyV1}
+	if o.jsonWebKey {
+		contexts = []string{diddoc.ContextDIDv1, diddoc.ContextJWS2020V1}
+	}
+	b := diddoc.NewBuilder().Context(contexts).Subject(did)
+
+	switch codec {
+	case multikey.X25519Pub:
+		// an encryption key can only be used for key agreement
+		b.VerificationMethod(verificationMethod).
+			KeyAgreement(verificationMethod.Id)
+
+	case multikey.Ed25519Pub:
+		encryptionKey, err := multikey.Ed25519ToX25519(pubKey.(ed25519.PublicKey))
+		if err != nil {
+			return nil, fmt.Errorf("%w: %v", diddoc.InvalidDid, err)
+		}
+		encryptionFingerprint, err := multikey.EncodePublicKey(encryptionKey)
+		if err != nil {
+			return nil, err
+		}
+		encryptionMethod, err := newVerificationMethod(did, encryptionFingerprint, encryptionKey, o)
+		if err != nil {
+			return nil, err
+		}
+		b.VerificationMethod([]diddoc.VerificationMethod{verificationMethod, encryptionMethod}).
+			Authentication(verificationMethod.Id).
+			AssertionMethod(verificationMethod.Id).
+			CapabilityInvocation(verificationMethod.Id).
+			CapabilityDelegation(verificationMethod.Id).
+			KeyAgreement(encryptionMethod.Id)
+
+	default:
+		// the elliptic curve keys are used for signing and ECDH key agreement
+		b.VerificationMethod(verificationMethod).
+			Authentication(verificationMethod.Id).
+			AssertionMethod(verificationMethod.Id).
+			CapabilityInvocation(verificationMethod.Id).
+			CapabilityDelegation(verificationMethod.Id).
+			KeyAgreement(verificationMethod.Id)
+	}
+	return b.Build()
+}
+
+func newVerificationMethod(did, fingerprint string, pubKey crypto.PublicKey, o options) (diddoc.VerificationMethod, error) {
+	verificationMethod := diddoc.VerificationMethod{
+		Id:         did + "#" + fingerprint,
+		Type:       diddoc.MultikeyType,
+		Controller: did,
+	}
+	if !o.jsonWebKey {
+		verificationMethod.PublicKeyMultibase = fingerprint
+		return verificationMethod, nil
+	}
+	key, err := jwkutil.FromPublicKey(pubKey)
+	if err != nil {
+		return diddoc.VerificationMethod{}, err
+	}
+	verificationMethod.Type = diddoc.JsonWebKey2020Type
+	verificationMethod.PubicKeyJWK = key
+	return verificationMethod, nil
+}
+
+// Resolver resolves did:key DIDs, it can be registered with a diddoc.Registry
+type Resolver struct {
+	options []Option
+}
+
+// NewResolver creates a did:key resolver
+func NewResolver(opts ...Option) *Resolver {
+	return &Resolver{options: opts}
+}
+
+// Resolve expands the did:key into a DID document, no network access is required
+func (r *Resolver) Resolve(ctx context.Context, did string, opts diddoc.ResolutionOptions) (*diddoc.Document, diddoc.ResolutionMetadata, diddoc.DocumentMetadata, error) {
+	doc, err := Expand(did, r.options...)
+	if err != nil {
+		return nil, diddoc.ResolutionMetadata{Error: diddoc.InvalidDid}, diddoc.DocumentMetadata{}, err
+	}
+	return doc, diddoc.ResolutionMetadata{}, diddoc.DocumentMetadata{}, nil
+}
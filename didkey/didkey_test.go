@@ -0,0 +1,135 @@
+// Copyright 2023 The Go SSI Framework Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+package didkey_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/gossif/diddoc"
+	"github.com/gossif/diddoc/didkey"
+	"github.com/lestrrat-go/jwx/v2/jwk"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestExpand(t *testing.T) {
+	type errorTestCases struct {
+		description          string
+		input                string
+		expectedKeyAgreement string
+		expectedSigning      bool
+		expectedError        string
+	}
+	for _, scenario := range []errorTestCases{
+		{description: "ed25519", input: "did:key:z6MkhaXgBZDvotDkL5257faiztiGiC2QtKLGpbnnEGta2doK",
+			expectedKeyAgreement: "did:key:z6MkhaXgBZDvotDkL5257faiztiGiC2QtKLGpbnnEGta2doK#z6LSj72tK8brWgZja8NLRwPigth2T9QRiG1uH9oKZuKjdh9p", expectedSigning: true},
+		{description: "x25519", input: "did:key:z6LSj72tK8brWgZja8NLRwPigth2T9QRiG1uH9oKZuKjdh9p",
+			expectedKeyAgreement: "did:key:z6LSj72tK8brWgZja8NLRwPigth2T9QRiG1uH9oKZuKjdh9p#z6LSj72tK8brWgZja8NLRwPigth2T9QRiG1uH9oKZuKjdh9p", expectedSigning: false},
+		{description: "p-256", input: "did:key:zDnaerDaTF5BXEavCrfRZEk316dpbLsfPDZ3WJ5hRTPFU2169",
+			expectedKeyAgreement: "did:key:zDnaerDaTF5BXEavCrfRZEk316dpbLsfPDZ3WJ5hRTPFU2169#zDnaerDaTF5BXEavCrfRZEk316dpbLsfPDZ3WJ5hRTPFU2169", expectedSigning: true},
+		{description: "p-384", input: "did:key:z82Lm1MpAkeJcix9K8TMiLd5NMAhnwkjjCBeWHXyu3U4oT2MVJJKXkcVBgjGhnLBn2Kaau9",
+			expectedKeyAgreement: "did:key:z82Lm1MpAkeJcix9K8TMiLd5NMAhnwkjjCBeWHXyu3U4oT2MVJJKXkcVBgjGhnLBn2Kaau9#z82Lm1MpAkeJcix9K8TMiLd5NMAhnwkjjCBeWHXyu3U4oT2MVJJKXkcVBgjGhnLBn2Kaau9", expectedSigning: true},
+		{description: "secp256k1", input: "did:key:zQ3shokFTS3brHcDQrn82RUDfCZESWL1ZdCEJwekUDPQiYBme",
+			expectedKeyAgreement: "did:key:zQ3shokFTS3brHcDQrn82RUDfCZESWL1ZdCEJwekUDPQiYBme#zQ3shokFTS3brHcDQrn82RUDfCZESWL1ZdCEJwekUDPQiYBme", expectedSigning: true},
+		{description: "other method", input: "did:example:z6MkhaXgBZDvotDkL5257faiztiGiC2QtKLGpbnnEGta2doK", expectedError: "invalidDid"},
+		{description: "not multibase", input: "did:key:123", expectedError: "invalidDid"},
+		{description: "base64url", input: "did:key:u7QEub8zjZwHceRSI4NCxdFzB4zpMHJ_MQcY700Pbvglw5g", expectedError: "invalidDid"},
+		{description: "base16", input: "did:key:fed012e6fcce36701dc791488e0d0b1745cc1e33a4c1c9fcc41c63bd343dbbe0970e6", expectedError: "invalidDid"},
+		{description: "unsupported codec", input: "did:key:z2J9gaYxrKVpdoG9A4gRnmpnRCcxU6agDtFVVBVdn1JedouoZN7SzcyREXXzWgt3gGiwpoHq7K68X4m32D8HgzG8wv3sY5j7", expectedError: "invalidDid"},
+	} {
+		t.Run(scenario.description, func(t *testing.T) {
+
+			doc, err := didkey.Expand(scenario.input)
+			if scenario.expectedError != "" {
+				assert.ErrorContains(t, err, scenario.expectedError)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, scenario.input, doc.Subject().String())
+			assert.Equal(t, []string{diddoc.ContextDIDv1, diddoc.ContextMultikeyV1}, doc.Context())
+
+			keyAgreement, err := doc.GetAssociatedVerificationMethod(diddoc.KeyAgreement)
+			require.NoError(t, err)
+			assert.Equal(t, scenario.expectedKeyAgreement, keyAgreement[0].Id)
+			assert.Equal(t, diddoc.MultikeyType, keyAgreement[0].Type)
+
+			authentication, err := doc.GetAssociatedVerificationMethod(diddoc.Authentication)
+			if !scenario.expectedSigning {
+				assert.ErrorContains(t, err, "not_found")
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, scenario.input+"#"+doc.Subject().ID, authentication[0].Id)
+			assert.Equal(t, doc.Subject().ID, authentication[0].PublicKeyMultibase)
+		})
+	}
+}
+
+func TestExpandJsonWebKey2020(t *testing.T) {
+	did := "did:key:zQ3shokFTS3brHcDQrn82RUDfCZESWL1ZdCEJwekUDPQiYBme"
+
+	doc, err := didkey.Expand(did, didkey.WithJsonWebKey2020())
+	require.NoError(t, err)
+	assert.Equal(t, []string{diddoc.ContextDIDv1, diddoc.ContextJWS2020V1}, doc.Context())
+
+	verificationMethod, err := doc.GetVerificationMethodById("#zQ3shokFTS3brHcDQrn82RUDfCZESWL1ZdCEJwekUDPQiYBme")
+	require.NoError(t, err)
+	assert.Equal(t, diddoc.JsonWebKey2020Type, verificationMethod.Type)
+
+	key, ok := verificationMethod.PubicKeyJWK.(jwk.Key)
+	require.True(t, ok)
+	assert.Equal(t, "secp256k1", key.(jwk.ECDSAPublicKey).Crv().String())
+
+	actualDID, err := didkey.New(key)
+	assert.NoError(t, err)
+	assert.Equal(t, did, actualDID.String())
+}
+
+func TestNew(t *testing.T) {
+	type errorTestCases struct {
+		description   string
+		input         interface{}
+		expectedDID   string
+		expectedError string
+	}
+	p256Key, _ := jwk.ParseKey([]byte(`{"kty":"EC","crv":"P-256","x":"igrFmi0whuihKnj9R3Om1SoMph72wUGeFaBbzG2vzns","y":"efsX5b10x8yjyrj4ny3pGfLcY7Xby1KzgqOdqnsrJIM"}`))
+	edKey, _ := jwk.ParseKey([]byte(`{"kty":"OKP","crv":"Ed25519","x":"Lm_M42cB3HkUiODQsXRcweM6TByfzEHGO9ND274JcOY","d":"ecWbCiPdNgdR_vZYTsWhU8Kr7xcKGZLQfsa5dTUt7Ts"}`))
+
+	for _, scenario := range []errorTestCases{
+		{description: "multibase", input: "z6MkhaXgBZDvotDkL5257faiztiGiC2QtKLGpbnnEGta2doK", expectedDID: "did:key:z6MkhaXgBZDvotDkL5257faiztiGiC2QtKLGpbnnEGta2doK"},
+		{description: "p-256 jwk", input: p256Key, expectedDID: "did:key:zDnaerx9CtbPJ1q36T5Ln5wYt3MQYeGRG5ehnPAmxcf5mDZpv"},
+		{description: "ed25519 private jwk", input: edKey},
+		{description: "unsupported key", input: 42, expectedError: "unsupported_key_type"},
+	} {
+		t.Run(scenario.description, func(t *testing.T) {
+
+			did, err := didkey.New(scenario.input)
+			if scenario.expectedError != "" {
+				assert.ErrorContains(t, err, scenario.expectedError)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, didkey.Method, did.Method)
+			if scenario.expectedDID != "" {
+				assert.Equal(t, scenario.expectedDID, did.String())
+			}
+			_, err = didkey.Expand(did.String())
+			assert.NoError(t, err)
+		})
+	}
+}
+
+func TestResolve(t *testing.T) {
+	registry := diddoc.NewRegistry()
+	registry.Register(didkey.Method, didkey.NewResolver())
+
+	doc, _, _, err := registry.Resolve(context.Background(), "did:key:z6MkhaXgBZDvotDkL5257faiztiGiC2QtKLGpbnnEGta2doK", diddoc.ResolutionOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, "did:key:z6MkhaXgBZDvotDkL5257faiztiGiC2QtKLGpbnnEGta2doK", doc.Subject().String())
+
+	_, resolutionMetadata, _, err := registry.Resolve(context.Background(), "did:key:zInvalid", diddoc.ResolutionOptions{})
+	assert.ErrorIs(t, err, diddoc.InvalidDid)
+	assert.Equal(t, diddoc.InvalidDid, resolutionMetadata.Error)
+}
@@ -0,0 +1,78 @@
+// Copyright 2023 The Go SSI Framework Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package didpeer implements the did:peer method for pairwise and n-wise DIDs, with
+// numalgo 0 (inception key), numalgo 2 (multiple encoded keys and services) and numalgo 4
+// (hash and long-form encoded document).
+package didpeer
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/gossif/diddoc"
+	"github.com/gossif/diddoc/didkey"
+)
+
+// Method is the DID method name
+const Method string = "peer"
+
+const (
+	Numalgo0 byte = '0'
+	Numalgo2 byte = '2'
+	Numalgo4 byte = '4'
+)
+
+var (
+	errUnsupportedNumalgo error = errors.New("unsupported_numalgo")
+)
+
+// NewNumalgo0 creates a did:peer:0 from the inception key, which is a JWK, a public key or a multibase encoded key
+func NewNumalgo0(key interface{}) (diddoc.DID, error) {
+	fingerprint, err := didkey.New(key)
+	if err != nil {
+		return diddoc.DID{}, err
+	}
+	return diddoc.ParseDID("did:" + Method + ":" + string(Numalgo0) + fingerprint.ID)
+}
+
+// Expand expands the did:peer into a DID document, the short form of numalgo 4 cannot be expanded
+func Expand(did string) (*diddoc.Document, error) {
+	parsed, err := diddoc.ParseDID(did)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", diddoc.InvalidDid, err)
+	}
+	if parsed.Method != Method {
+		return nil, fmt.Errorf("%w: method %q is not %q", diddoc.InvalidDid, parsed.Method, Method)
+	}
+	switch parsed.ID[0] {
+	case Numalgo0:
+		return didkey.ExpandFingerprint(did, parsed.ID[1:])
+	case Numalgo2:
+		return expandNumalgo2(did, parsed.ID[1:])
+	case Numalgo4:
+		return expandNumalgo4(did, parsed.ID[1:])
+	}
+	return nil, fmt.Errorf("%w: %v", diddoc.InvalidDid, errUnsupportedNumalgo)
+}
+
+// Resolver resolves did:peer DIDs, it can be registered with a diddoc.Registry
+type Resolver struct{}
+
+// NewResolver creates a did:peer resolver
+func NewResolver() *Resolver {
+	return &Resolver{}
+}
+
+// Resolve expands the did:peer into a DID document, no network access is required
+func (r *Resolver) Resolve(ctx context.Context, did string, opts diddoc.ResolutionOptions) (*diddoc.Document, diddoc.ResolutionMetadata, diddoc.DocumentMetadata, error) {
+	doc, err := Expand(did)
+	if err != nil {
+		code := diddoc.InvalidDid
+		errors.As(err, &code)
+		return nil, diddoc.ResolutionMetadata{Error: code}, diddoc.DocumentMetadata{}, err
+	}
+	return doc, diddoc.ResolutionMetadata{}, diddoc.DocumentMetadata{}, nil
+}
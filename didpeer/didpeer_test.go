@@ -0,0 +1,150 @@
+// Copyright 2023 The Go SSI Framework Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+package didpeer_test
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/gossif/diddoc"
+	"github.com/gossif/diddoc/didpeer"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const (
+	ed25519Key string = "z6MkqRYqQiSgvZQdnBytw86Qbs2ZWUkGv22od935YF4s8M7V"
+	x25519Key  string = "z6LSbysY2xFMRpGMhb7tFTLMpeuPRaqaWM1yECx2AtzE3KCc"
+)
+
+func TestNumalgo0(t *testing.T) {
+	did, err := didpeer.NewNumalgo0(ed25519Key)
+	require.NoError(t, err)
+	assert.Equal(t, "did:peer:0"+ed25519Key, did.String())
+
+	doc, err := didpeer.Expand(did.String())
+	require.NoError(t, err)
+	assert.Equal(t, did.String(), doc.Subject().String())
+
+	authentication, err := doc.GetAssociatedVerificationMethod(diddoc.Authentication)
+	require.NoError(t, err)
+	assert.Equal(t, ed25519Key, authentication[0].PublicKeyMultibase)
+
+	keyAgreement, err := doc.GetAssociatedVerificationMethod(diddoc.KeyAgreement)
+	require.NoError(t, err)
+	assert.True(t, strings.HasPrefix(keyAgreement[0].PublicKeyMultibase, "z6LS"))
+}
+
+func TestNumalgo2(t *testing.T) {
+	keys := []didpeer.Key{
+		{Purpose: diddoc.KeyAgreement, Key: x25519Key},
+		{Purpose: diddoc.Authentication, Key: ed25519Key},
+	}
+	didcommEndpoint, err := diddoc.NewServiceEndpoint(diddoc.DIDCommEndpoint{
+		URI:         "https://example.com/endpoint",
+		Accept:      []string{"didcomm/v2", "didcomm/aip2;env=rfc587"},
+		RoutingKeys: []string{"did:example:somemediator#somekey"},
+	})
+	require.NoError(t, err)
+	services := []diddoc.Service{
+		{Type: diddoc.DIDCommMessagingType, ServiceEndpoint: didcommEndpoint},
+		{Id: "#linked-domain", Type: diddoc.LinkedDomainsType, ServiceEndpoint: diddoc.URIServiceEndpoint("https://bar.example.com")},
+	}
+	did, err := didpeer.NewNumalgo2(keys, services)
+	require.NoError(t, err)
+	assert.True(t, strings.HasPrefix(did.String(), "did:peer:2.E"+x25519Key+".V"+ed25519Key+".S"))
+
+	doc, err := didpeer.Expand(did.String())
+	require.NoError(t, err)
+	assert.Equal(t, did.String(), doc.Subject().String())
+
+	keyAgreement, err := doc.GetAssociatedVerificationMethod(diddoc.KeyAgreement)
+	require.NoError(t, err)
+	assert.Equal(t, []diddoc.VerificationMethod{{Id: "#key-1", Type: diddoc.MultikeyType, Controller: did.String(), PublicKeyMultibase: x25519Key}}, keyAgreement)
+
+	authentication, err := doc.GetAssociatedVerificationMethod(diddoc.Authentication)
+	require.NoError(t, err)
+	assert.Equal(t, []diddoc.VerificationMethod{{Id: "#key-2", Type: diddoc.MultikeyType, Controller: did.String(), PublicKeyMultibase: ed25519Key}}, authentication)
+
+	services[0].Id = "#service"
+	assert.Equal(t, services, doc.Services())
+}
+
+func TestNumalgo2Abbreviations(t *testing.T) {
+	// the service block is {"t":"dm","s":{"uri":"http://example.com/didcomm","a":["didcomm/v2"],"r":["did:example:123456789abcdefghi#key-1"]}}
+	did := "did:peer:2.Ez6LSbysY2xFMRpGMhb7tFTLMpeuPRaqaWM1yECx2AtzE3KCc.SeyJ0IjoiZG0iLCJzIjp7InVyaSI6Imh0dHA6Ly9leGFtcGxlLmNvbS9kaWRjb21tIiwiYSI6WyJkaWRjb21tL3YyIl0sInIiOlsiZGlkOmV4YW1wbGU6MTIzNDU2Nzg5YWJjZGVmZ2hpI2tleS0xIl19fQ"
+
+	doc, err := didpeer.Expand(did)
+	require.NoError(t, err)
+
+	services, ok := doc.Services().([]diddoc.Service)
+	require.True(t, ok)
+	require.Len(t, services, 1)
+	assert.Equal(t, "#service", services[0].Id)
+
+	endpoints, err := services[0].DIDCommEndpoints()
+	require.NoError(t, err)
+	expectedEndpoints := []diddoc.DIDCommEndpoint{
+		{URI: "http://example.com/didcomm", Accept: []string{"didcomm/v2"}, RoutingKeys: []string{"did:example:123456789abcdefghi#key-1"}},
+	}
+	assert.Equal(t, expectedEndpoints, endpoints)
+}
+
+func TestNumalgo4(t *testing.T) {
+	input, err := diddoc.NewBuilder().
+		Context([]string{diddoc.ContextDIDv1, diddoc.ContextMultikeyV1}).
+		VerificationMethod(diddoc.VerificationMethod{Id: "#key-1", Type: diddoc.MultikeyType, PublicKeyMultibase: ed25519Key}).
+		Authentication("#key-1").
+		Build()
+	require.NoError(t, err)
+
+	did, err := didpeer.NewNumalgo4(input)
+	require.NoError(t, err)
+	shortForm, err := didpeer.ShortForm(did.String())
+	require.NoError(t, err)
+
+	doc, err := didpeer.Expand(did.String())
+	require.NoError(t, err)
+	assert.Equal(t, did.String(), doc.Subject().String())
+	assert.Equal(t, []string{shortForm.String()}, doc.AlsoKnownAs())
+
+	authentication, err := doc.GetAssociatedVerificationMethod(diddoc.Authentication)
+	require.NoError(t, err)
+	assert.Equal(t, did.String(), authentication[0].Controller)
+
+	_, err = didpeer.Expand(shortForm.String())
+	assert.ErrorIs(t, err, diddoc.NotFound)
+
+	tampered := strings.Replace(did.String(), shortForm.ID, shortForm.ID[:len(shortForm.ID)-1]+"1", 1)
+	_, err = didpeer.Expand(tampered)
+	assert.ErrorIs(t, err, diddoc.InvalidDid)
+}
+
+func TestResolve(t *testing.T) {
+	type errorTestCases struct {
+		description   string
+		input         string
+		expectedError diddoc.ResolutionError
+	}
+	for _, scenario := range []errorTestCases{
+		{description: "numalgo 0", input: "did:peer:0" + ed25519Key},
+		{description: "numalgo 2", input: "did:peer:2.V" + ed25519Key},
+		{description: "unsupported numalgo", input: "did:peer:1zQmZMygzYqNwU6Uhmewx5Xepf2VLp5S4HLSwwgf2aiKZuwa", expectedError: diddoc.InvalidDid},
+		{description: "unsupported purpose", input: "did:peer:2.X" + ed25519Key, expectedError: diddoc.InvalidDid},
+		{description: "short form numalgo 4", input: "did:peer:4zQmd8CpeFPci817KDsbSAKWcXAE2mjvCQSasRewvbSF54Bd", expectedError: diddoc.NotFound},
+	} {
+		t.Run(scenario.description, func(t *testing.T) {
+
+			doc, resolutionMetadata, _, err := didpeer.NewResolver().Resolve(context.Background(), scenario.input, diddoc.ResolutionOptions{})
+			if scenario.expectedError != "" {
+				assert.ErrorIs(t, err, scenario.expectedError)
+				assert.Equal(t, scenario.expectedError, resolutionMetadata.Error)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, scenario.input, doc.Subject().String())
+		})
+	}
+}
@@ -0,0 +1,269 @@
+// Copyright 2023 The Go SSI Framework Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+package didpeer
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/gossif/diddoc"
+	"github.com/gossif/diddoc/didkey"
+	"github.com/gossif/diddoc/multikey"
+)
+
+const (
+	purposeService byte = 'S'
+
+	elementSeparator string = "."
+	typeKey          string = "type"
+	abbreviatedType  string = "t"
+)
+
+var (
+	errUnsupportedPurpose error = errors.New("unsupported_purpose")
+	errInvalidService     error = errors.New("invalid_service")
+	errInvalidElement     error = errors.New("invalid_element")
+
+	// purposeCodes are the prefixes of the encoded keys
+	purposeCodes = map[diddoc.ProofPurpose]byte{
+		diddoc.AssertionMethod:      'A',
+		diddoc.KeyAgreement:         'E',
+		diddoc.Authentication:       'V',
+		diddoc.CapabilityInvocation: 'I',
+		diddoc.CapabilityDelegation: 'D',
+	}
+	// purposeOrder is the order of the verification relationships in the expanded document
+	purposeOrder = []diddoc.ProofPurpose{
+		diddoc.Authentication,
+		diddoc.AssertionMethod,
+		diddoc.KeyAgreement,
+		diddoc.CapabilityInvocation,
+		diddoc.CapabilityDelegation,
+	}
+
+	serviceKeyAbbreviations = map[string]string{
+		"type":            "t",
+		"serviceEndpoint": "s",
+		"routingKeys":     "r",
+		"accept":          "a",
+	}
+	serviceTypeAbbreviations = map[string]string{
+		"DIDCommMessaging": "dm",
+	}
+)
+
+// Key is a key of a did:peer:2 with its purpose
+type Key struct {
+	Purpose diddoc.ProofPurpose
+	// Key is a JWK, a public key or a multibase encoded key
+	Key interface{}
+}
+
+// NewNumalgo2 creates a did:peer:2 from the keys and the services
+func NewNumalgo2(keys []Key, services []diddoc.Service) (diddoc.DID, error) {
+	var sb strings.Builder
+	sb.WriteString("did:" + Method + ":" + string(Numalgo2))
+
+	for _, key := range keys {
+		code, ok := purposeCodes[key.Purpose]
+		if !ok {
+			return diddoc.DID{}, errUnsupportedPurpose
+		}
+		fingerprint, err := didkey.New(key.Key)
+		if err != nil {
+			return diddoc.DID{}, err
+		}
+		sb.WriteString(elementSeparator + string(code) + fingerprint.ID)
+	}
+	for i, service := range services {
+		encoded, err := encodeService(service, i)
+		if err != nil {
+			return diddoc.DID{}, err
+		}
+		sb.WriteString(elementSeparator + string(purposeService) + encoded)
+	}
+	return diddoc.ParseDID(sb.String())
+}
+
+func expandNumalgo2(did string, elements string) (*diddoc.Document, error) {
+	if !strings.HasPrefix(elements, elementSeparator) {
+		return nil, fmt.Errorf("%w: %v", diddoc.InvalidDid, errInvalidElement)
+	}
+	var (
+		verificationMethods []diddoc.VerificationMethod
+		services            []diddoc.Service
+		relationships       = map[diddoc.ProofPurpose][]string{}
+	)
+	for _, element := range strings.Split(elements[1:], elementSeparator) {
+		if len(element) < 2 {
+			return nil, fmt.Errorf("%w: %v", diddoc.InvalidDid, errInvalidElement)
+		}
+		code, value := element[0], element[1:]
+
+		if code == purposeService {
+			decoded, err := decodeService(value, len(services))
+			if err != nil {
+				return nil, fmt.Errorf("%w: %v", diddoc.InvalidDid, err)
+			}
+			services = append(services, decoded...)
+			continue
+		}
+		purpose, err := purposeOf(code)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %v", diddoc.InvalidDid, err)
+		}
+		codec, data, err := multikey.Decode(value)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %v", diddoc.InvalidDid, err)
+		}
+		if _, err := multikey.UnmarshalPublicKey(codec, data); err != nil {
+			return nil, fmt.Errorf("%w: %v", diddoc.InvalidDid, err)
+		}
+		verificationMethod := diddoc.VerificationMethod{
+			Id:                 "#key-" + strconv.Itoa(len(verificationMethods)+1),
+			Type:               diddoc.MultikeyType,
+			Controller:         did,
+			PublicKeyMultibase: value,
+		}
+		verificationMethods = append(verificationMethods, verificationMethod)
+		relationships[purpose] = append(relationships[purpose], verificationMethod.Id)
+	}
+
+	b := diddoc.NewBuilder().
+		Context([]string{diddoc.ContextDIDv1, diddoc.ContextMultikeyV1}).
+		Subject(did)
+	if len(verificationMethods) > 0 {
+		b.VerificationMethod(verificationMethods)
+	}
+	for _, purpose := range purposeOrder {
+		if ids, ok := relationships[purpose]; ok {
+			b.VerificationRelationship(purpose, ids)
+		}
+	}
+	if len(services) > 0 {
+		b.Service(services)
+	}
+	return b.Build()
+}
+
+func purposeOf(code byte) (diddoc.ProofPurpose, error) {
+	for purpose, purposeCode := range purposeCodes {
+		if purposeCode == code {
+			return purpose, nil
+		}
+	}
+	return "", errUnsupportedPurpose
+}
+
+// defaultServiceId returns the id of a service without an explicit id, which depends on its position
+func defaultServiceId(index int) string {
+	if index == 0 {
+		return "#service"
+	}
+	return "#service-" + strconv.Itoa(index)
+}
+
+func encodeService(service diddoc.Service, index int) (string, error) {
+	properties := map[string]interface{}{
+		"type":            service.Type,
+		"serviceEndpoint": service.ServiceEndpoint,
+	}
+	if service.Id != "" && service.Id != defaultServiceId(index) {
+		properties["id"] = service.Id
+	}
+	// normalize the endpoint into generic JSON values before abbreviating
+	data, err := json.Marshal(properties)
+	if err != nil {
+		return "", err
+	}
+	var generic interface{}
+	if err := json.Unmarshal(data, &generic); err != nil {
+		return "", err
+	}
+	data, err = json.Marshal(replaceKeys(generic, serviceKeyAbbreviations, abbreviatedType, serviceTypeAbbreviations))
+	if err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(data), nil
+}
+
+// decodeService decodes an encoded service block, which is a single service or a list of services
+func decodeService(value string, index int) ([]diddoc.Service, error) {
+	data, err := base64.RawURLEncoding.DecodeString(strings.TrimRight(value, "="))
+	if err != nil {
+		return nil, errInvalidService
+	}
+	var generic interface{}
+	if err := json.Unmarshal(data, &generic); err != nil {
+		return nil, errInvalidService
+	}
+	blocks, ok := generic.([]interface{})
+	if !ok {
+		blocks = []interface{}{generic}
+	}
+	services := make([]diddoc.Service, 0, len(blocks))
+	for _, block := range blocks {
+		properties, ok := replaceKeys(block, invert(serviceKeyAbbreviations), typeKey, invert(serviceTypeAbbreviations)).(map[string]interface{})
+		if !ok {
+			return nil, errInvalidService
+		}
+		serviceType, _ := properties["type"].(string)
+		if serviceType == "" {
+			return nil, errInvalidService
+		}
+		serviceEndpoint, err := diddoc.NewServiceEndpoint(properties["serviceEndpoint"])
+		if err != nil {
+			return nil, errInvalidService
+		}
+		id, _ := properties["id"].(string)
+		if id == "" {
+			id = defaultServiceId(index + len(services))
+		}
+		services = append(services, diddoc.Service{
+			Id:              id,
+			Type:            serviceType,
+			ServiceEndpoint: serviceEndpoint,
+		})
+	}
+	return services, nil
+}
+
+// replaceKeys replaces the map keys, and the values of the type key, recursively
+func replaceKeys(v interface{}, keys map[string]string, typeField string, types map[string]string) interface{} {
+	switch value := v.(type) {
+	case map[string]interface{}:
+		replaced := make(map[string]interface{}, len(value))
+		for key, item := range value {
+			if k, ok := keys[key]; ok {
+				key = k
+			}
+			if s, ok := item.(string); ok && key == typeField {
+				if t, ok := types[s]; ok {
+					item = t
+				}
+			}
+			replaced[key] = replaceKeys(item, keys, typeField, types)
+		}
+		return replaced
+	case []interface{}:
+		replaced := make([]interface{}, 0, len(value))
+		for _, item := range value {
+			replaced = append(replaced, replaceKeys(item, keys, typeField, types))
+		}
+		return replaced
+	}
+	return v
+}
+
+func invert(m map[string]string) map[string]string {
+	inverted := make(map[string]string, len(m))
+	for k, v := range m {
+		inverted[v] = k
+	}
+	return inverted
+}
@@ -0,0 +1,107 @@
+// Copyright 2023 The Go SSI Framework Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+package didpeer
+
+import (
+	"crypto/sha256"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/gossif/diddoc"
+	"github.com/gossif/diddoc/multikey"
+)
+
+const (
+	idKey                 string = "id"
+	alsoKnownAsKey        string = "alsoKnownAs"
+	controllerKey         string = "controller"
+	verificationMethodKey string = "verificationMethod"
+)
+
+var (
+	errIdNotAllowed error = errors.New("id_not_allowed")
+	errHashMismatch error = errors.New("hash_mismatch")
+)
+
+// NewNumalgo4 creates the long form did:peer:4 from the input document, which must not have an id
+func NewNumalgo4(doc *diddoc.Document) (diddoc.DID, error) {
+	if doc.Get(idKey) != nil {
+		return diddoc.DID{}, errIdNotAllowed
+	}
+	// the canonical form makes the DID independent of the property order of the input document
+	data, err := doc.Canonicalize()
+	if err != nil {
+		return diddoc.DID{}, err
+	}
+	encoded := multikey.Encode(multikey.JSON, data)
+	return diddoc.ParseDID("did:" + Method + ":" + string(Numalgo4) + numalgo4Hash(encoded) + ":" + encoded)
+}
+
+// ShortForm returns the short form of a long form did:peer:4
+func ShortForm(did string) (diddoc.DID, error) {
+	parsed, err := diddoc.ParseDID(did)
+	if err != nil {
+		return diddoc.DID{}, err
+	}
+	if parsed.Method != Method || parsed.ID[0] != Numalgo4 {
+		return diddoc.DID{}, fmt.Errorf("%w: %v", diddoc.InvalidDid, errUnsupportedNumalgo)
+	}
+	return diddoc.ParseDID("did:" + Method + ":" + parsed.Segments[0])
+}
+
+// numalgo4Hash is the multibase encoded sha2-256 multihash of the encoded document
+func numalgo4Hash(encoded string) string {
+	digest := sha256.Sum256([]byte(encoded))
+	return multikey.Encode(multikey.SHA256, append([]byte{sha256.Size}, digest[:]...))
+}
+
+func expandNumalgo4(did string, id string) (*diddoc.Document, error) {
+	hash, encoded, found := strings.Cut(id, ":")
+	if !found {
+		// the document of the short form is only known to those who received the long form
+		return nil, fmt.Errorf("%w: short form %s", diddoc.NotFound, did)
+	}
+	if numalgo4Hash(encoded) != hash {
+		return nil, fmt.Errorf("%w: %v", diddoc.InvalidDid, errHashMismatch)
+	}
+	codec, data, err := multikey.Decode(encoded)
+	if err != nil || codec != multikey.JSON {
+		return nil, fmt.Errorf("%w: %v", diddoc.InvalidDid, err)
+	}
+	var properties map[string]interface{}
+	if err := json.Unmarshal(data, &properties); err != nil {
+		return nil, fmt.Errorf("%w: %v", diddoc.InvalidDid, err)
+	}
+	// contextualize the input document with the long form as id and the short form as alias
+	properties[idKey] = did
+	alsoKnownAs, _ := properties[alsoKnownAsKey].([]interface{})
+	properties[alsoKnownAsKey] = append(alsoKnownAs, "did:"+Method+":"+string(Numalgo4)+hash)
+	contextualizeControllers(properties[verificationMethodKey], did)
+	for _, purpose := range purposeOrder {
+		contextualizeControllers(properties[purpose.String()], did)
+	}
+
+	if data, err = json.Marshal(properties); err != nil {
+		return nil, err
+	}
+	doc := diddoc.NewDocument()
+	if err := doc.UnmarshalJSON(data); err != nil {
+		return nil, fmt.Errorf("%w: %v", diddoc.InvalidDid, err)
+	}
+	return doc, nil
+}
+
+// contextualizeControllers sets the controller of the embedded verification methods without a controller
+func contextualizeControllers(v interface{}, did string) {
+	verificationMethods, _ := v.([]interface{})
+	for _, verificationMethod := range verificationMethods {
+		if properties, ok := verificationMethod.(map[string]interface{}); ok {
+			if _, ok := properties[controllerKey]; !ok {
+				properties[controllerKey] = did
+			}
+		}
+	}
+}
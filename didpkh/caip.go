@@ -0,0 +1,67 @@
+// Copyright 2023 The Go SSI Framework Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+package didpkh
+
+import (
+	"errors"
+	"regexp"
+	"strings"
+)
+
+var (
+	errInvalidChainId   error = errors.New("invalid_chain_id")
+	errInvalidAccountId error = errors.New("invalid_account_id")
+
+	namespacePattern = regexp.MustCompile(`^[-a-z0-9]{3,8}$`)
+	referencePattern = regexp.MustCompile(`^[-_a-zA-Z0-9]{1,32}$`)
+	addressPattern   = regexp.MustCompile(`^[-.%a-zA-Z0-9]{1,128}$`)
+)
+
+// ChainId is a CAIP-2 blockchain id, e.g. eip155:1 for the Ethereum mainnet
+type ChainId struct {
+	Namespace string
+	Reference string
+}
+
+// ParseChainId parses a CAIP-2 blockchain id
+func ParseChainId(s string) (ChainId, error) {
+	namespace, reference, found := strings.Cut(s, ":")
+	if !found || !namespacePattern.MatchString(namespace) || !referencePattern.MatchString(reference) {
+		return ChainId{}, errInvalidChainId
+	}
+	return ChainId{Namespace: namespace, Reference: reference}, nil
+}
+
+// String returns the CAIP-2 representation of the blockchain id
+func (c ChainId) String() string {
+	return c.Namespace + ":" + c.Reference
+}
+
+// AccountId is a CAIP-10 account id, e.g. eip155:1:0xab16a96d359ec26a11e2c2b3d8f8b8942d5bfcdb
+type AccountId struct {
+	ChainId ChainId
+	Address string
+}
+
+// ParseAccountId parses a CAIP-10 account id
+func ParseAccountId(s string) (AccountId, error) {
+	i := strings.LastIndex(s, ":")
+	if i < 0 {
+		return AccountId{}, errInvalidAccountId
+	}
+	chainId, err := ParseChainId(s[:i])
+	if err != nil {
+		return AccountId{}, err
+	}
+	address := s[i+1:]
+	if !addressPattern.MatchString(address) {
+		return AccountId{}, errInvalidAccountId
+	}
+	return AccountId{ChainId: chainId, Address: address}, nil
+}
+
+// String returns the CAIP-10 representation of the account id
+func (a AccountId) String() string {
+	return a.ChainId.String() + ":" + a.Address
+}
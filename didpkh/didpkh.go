@@ -0,0 +1,164 @@
+// Copyright 2023 The Go SSI Framework Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package didpkh implements the did:pkh method, which derives a DID from a CAIP-10 blockchain account.
+package didpkh
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/gossif/diddoc"
+)
+
+// Method is the DID method name
+const Method string = "pkh"
+
+const (
+	EIP155 string = "eip155"
+	BIP122 string = "bip122"
+	Solana string = "solana"
+	Tezos  string = "tezos"
+)
+
+const (
+	EcdsaSecp256k1RecoveryMethod2020Type                          string = "EcdsaSecp256k1RecoveryMethod2020"
+	Ed25519VerificationKey2018Type                                string = "Ed25519VerificationKey2018"
+	Ed25519PublicKeyBLAKE2BDigestSize20Base58CheckEncoded2021Type string = "Ed25519PublicKeyBLAKE2BDigestSize20Base58CheckEncoded2021"
+	P256PublicKeyBLAKE2BDigestSize20Base58CheckEncoded2021Type    string = "P256PublicKeyBLAKE2BDigestSize20Base58CheckEncoded2021"
+
+	contextSecp256k1Recovery2020 string = "https://w3id.org/security/suites/secp256k1recovery-2020/v2"
+	contextEd25519Signature2018  string = "https://w3id.org/security/suites/ed25519-2018/v1"
+
+	verificationMethodFragment string = "blockchainAccountId"
+)
+
+var (
+	errUnsupportedNamespace error = errors.New("unsupported_namespace")
+	errInvalidAddress       error = errors.New("invalid_address")
+
+	eip155AddressPattern = regexp.MustCompile(`^0x[0-9a-fA-F]{40}$`)
+	solanaAddressPattern = regexp.MustCompile(`^[1-9A-HJ-NP-Za-km-z]{32,44}$`)
+	tezosAddressPattern  = regexp.MustCompile(`^tz[123][1-9A-HJ-NP-Za-km-z]{33}$`)
+)
+
+// New creates a did:pkh from a CAIP-10 account id, which is an AccountId or its string representation
+func New(account interface{}) (diddoc.DID, error) {
+	var accountId AccountId
+	switch a := account.(type) {
+	case AccountId:
+		accountId = a
+	case string:
+		var err error
+		if accountId, err = ParseAccountId(a); err != nil {
+			return diddoc.DID{}, err
+		}
+	default:
+		return diddoc.DID{}, errInvalidAccountId
+	}
+	if _, err := verificationMethodType(accountId); err != nil {
+		return diddoc.DID{}, err
+	}
+	return diddoc.ParseDID("did:" + Method + ":" + accountId.String())
+}
+
+// AccountIdOf returns the CAIP-10 account id of the did:pkh
+func AccountIdOf(did string) (AccountId, error) {
+	parsed, err := diddoc.ParseDID(did)
+	if err != nil {
+		return AccountId{}, fmt.Errorf("%w: %v", diddoc.InvalidDid, err)
+	}
+	if parsed.Method != Method {
+		return AccountId{}, fmt.Errorf("%w: method %q is not %q", diddoc.InvalidDid, parsed.Method, Method)
+	}
+	accountId, err := ParseAccountId(parsed.ID)
+	if err != nil {
+		return AccountId{}, fmt.Errorf("%w: %v", diddoc.InvalidDid, err)
+	}
+	return accountId, nil
+}
+
+// Expand expands the did:pkh into a DID document with a blockchainAccountId verification method
+func Expand(did string) (*diddoc.Document, error) {
+	accountId, err := AccountIdOf(did)
+	if err != nil {
+		return nil, err
+	}
+	methodType, err := verificationMethodType(accountId)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", diddoc.InvalidDid, err)
+	}
+	verificationMethod := diddoc.VerificationMethod{
+		Id:                  did + "#" + verificationMethodFragment,
+		Type:                methodType,
+		Controller:          did,
+		BlockchainAccountId: accountId.String(),
+	}
+	contexts := []string{diddoc.ContextDIDv1}
+	switch methodType {
+	case EcdsaSecp256k1RecoveryMethod2020Type:
+		contexts = append(contexts, contextSecp256k1Recovery2020)
+	case Ed25519VerificationKey2018Type:
+		contexts = append(contexts, contextEd25519Signature2018)
+	}
+	return diddoc.NewBuilder().
+		Context(contexts).
+		Subject(did).
+		VerificationMethod(verificationMethod).
+		Authentication(verificationMethod.Id).
+		AssertionMethod(verificationMethod.Id).
+		Build()
+}
+
+// verificationMethodType returns the verification method type of the account, which depends on the namespace
+func verificationMethodType(accountId AccountId) (string, error) {
+	address := accountId.Address
+	switch accountId.ChainId.Namespace {
+	case EIP155:
+		if !eip155AddressPattern.MatchString(address) {
+			return "", errInvalidAddress
+		}
+		return EcdsaSecp256k1RecoveryMethod2020Type, nil
+	case BIP122:
+		return EcdsaSecp256k1RecoveryMethod2020Type, nil
+	case Solana:
+		if !solanaAddressPattern.MatchString(address) {
+			return "", errInvalidAddress
+		}
+		return Ed25519VerificationKey2018Type, nil
+	case Tezos:
+		if !tezosAddressPattern.MatchString(address) {
+			return "", errInvalidAddress
+		}
+		switch {
+		case strings.HasPrefix(address, "tz1"):
+			return Ed25519PublicKeyBLAKE2BDigestSize20Base58CheckEncoded2021Type, nil
+		case strings.HasPrefix(address, "tz2"):
+			return EcdsaSecp256k1RecoveryMethod2020Type, nil
+		default:
+			return P256PublicKeyBLAKE2BDigestSize20Base58CheckEncoded2021Type, nil
+		}
+	}
+	return "", errUnsupportedNamespace
+}
+
+// Resolver resolves did:pkh DIDs, it can be registered with a diddoc.Registry
+type Resolver struct{}
+
+// NewResolver creates a did:pkh resolver
+func NewResolver() *Resolver {
+	return &Resolver{}
+}
+
+// Resolve expands the did:pkh into a DID document, no network access is required
+func (r *Resolver) Resolve(ctx context.Context, did string, opts diddoc.ResolutionOptions) (*diddoc.Document, diddoc.ResolutionMetadata, diddoc.DocumentMetadata, error) {
+	doc, err := Expand(did)
+	if err != nil {
+		return nil, diddoc.ResolutionMetadata{Error: diddoc.InvalidDid}, diddoc.DocumentMetadata{}, err
+	}
+	return doc, diddoc.ResolutionMetadata{}, diddoc.DocumentMetadata{}, nil
+}
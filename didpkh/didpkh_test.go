@@ -0,0 +1,102 @@
+// Copyright 2023 The Go SSI Framework Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+package didpkh_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/gossif/diddoc"
+	"github.com/gossif/diddoc/didpkh"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseAccountId(t *testing.T) {
+	type errorTestCases struct {
+		description       string
+		input             string
+		expectedNamespace string
+		expectedReference string
+		expectedAddress   string
+		expectedError     string
+	}
+	for _, scenario := range []errorTestCases{
+		{description: "ethereum", input: "eip155:1:0xab16a96d359ec26a11e2c2b3d8f8b8942d5bfcdb", expectedNamespace: "eip155", expectedReference: "1", expectedAddress: "0xab16a96d359ec26a11e2c2b3d8f8b8942d5bfcdb"},
+		{description: "bitcoin", input: "bip122:000000000019d6689c085ae165831e93:128Lkh3S7CkDTBZ8W7BbpsN3YYizJMp8p6", expectedNamespace: "bip122", expectedReference: "000000000019d6689c085ae165831e93", expectedAddress: "128Lkh3S7CkDTBZ8W7BbpsN3YYizJMp8p6"},
+		{description: "no address", input: "eip155:1", expectedError: "invalid_chain_id"},
+		{description: "short namespace", input: "ei:1:0xab16a96d359ec26a11e2c2b3d8f8b8942d5bfcdb", expectedError: "invalid_chain_id"},
+		{description: "long reference", input: "eip155:123456789012345678901234567890123:0xab16a96d359ec26a11e2c2b3d8f8b8942d5bfcdb", expectedError: "invalid_chain_id"},
+		{description: "invalid address", input: "eip155:1:0x ab", expectedError: "invalid_account_id"},
+	} {
+		t.Run(scenario.description, func(t *testing.T) {
+
+			accountId, err := didpkh.ParseAccountId(scenario.input)
+			if scenario.expectedError != "" {
+				assert.ErrorContains(t, err, scenario.expectedError)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, scenario.expectedNamespace, accountId.ChainId.Namespace)
+			assert.Equal(t, scenario.expectedReference, accountId.ChainId.Reference)
+			assert.Equal(t, scenario.expectedAddress, accountId.Address)
+			assert.Equal(t, scenario.input, accountId.String())
+		})
+	}
+}
+
+func TestExpand(t *testing.T) {
+	type errorTestCases struct {
+		description   string
+		input         string
+		expectedType  string
+		expectedError string
+	}
+	for _, scenario := range []errorTestCases{
+		{description: "ethereum", input: "did:pkh:eip155:1:0xb9c5714089478a327f09197987f16f9e5d936e8a", expectedType: didpkh.EcdsaSecp256k1RecoveryMethod2020Type},
+		{description: "bitcoin", input: "did:pkh:bip122:000000000019d6689c085ae165831e93:128Lkh3S7CkDTBZ8W7BbpsN3YYizJMp8p6", expectedType: didpkh.EcdsaSecp256k1RecoveryMethod2020Type},
+		{description: "solana", input: "did:pkh:solana:4sGjMW1sUnHzSxGspuhpqLDx6wiyjNtZ:CKg5d12Jhpej1JqtmxLJgaFqqeYjxgPqToJ4LBdvG9Ev", expectedType: didpkh.Ed25519VerificationKey2018Type},
+		{description: "tezos tz1", input: "did:pkh:tezos:NetXdQprcVkpaWU:tz1TzrmTBSuiVHV2VfMnGRMYvTEPCP42oSM8", expectedType: didpkh.Ed25519PublicKeyBLAKE2BDigestSize20Base58CheckEncoded2021Type},
+		{description: "tezos tz2", input: "did:pkh:tezos:NetXdQprcVkpaWU:tz2BFTyPeYRzxd5aiBchbXN3WCZhx7BqbMBq", expectedType: didpkh.EcdsaSecp256k1RecoveryMethod2020Type},
+		{description: "invalid ethereum address", input: "did:pkh:eip155:1:0xb9c5714089478a327f09197987f16f9e5d936e8", expectedError: "invalid_address"},
+		{description: "unsupported namespace", input: "did:pkh:cosmos:cosmoshub-3:cosmos1t2uflqwqe0fsj0shcfkrvpukewcw40yjj6hdc0", expectedError: "unsupported_namespace"},
+		{description: "other method", input: "did:example:123", expectedError: "invalidDid"},
+	} {
+		t.Run(scenario.description, func(t *testing.T) {
+
+			doc, err := didpkh.Expand(scenario.input)
+			if scenario.expectedError != "" {
+				assert.ErrorContains(t, err, scenario.expectedError)
+				assert.ErrorIs(t, err, diddoc.InvalidDid)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, scenario.input, doc.Subject().String())
+
+			for _, purpose := range []diddoc.ProofPurpose{diddoc.Authentication, diddoc.AssertionMethod} {
+				verificationMethods, err := doc.GetAssociatedVerificationMethod(purpose)
+				require.NoError(t, err)
+				assert.Equal(t, scenario.input+"#blockchainAccountId", verificationMethods[0].Id)
+				assert.Equal(t, scenario.expectedType, verificationMethods[0].Type)
+				assert.Equal(t, scenario.input[len("did:pkh:"):], verificationMethods[0].BlockchainAccountId)
+			}
+		})
+	}
+}
+
+func TestNew(t *testing.T) {
+	accountId, err := didpkh.ParseAccountId("eip155:1:0xb9c5714089478a327f09197987f16f9e5d936e8a")
+	require.NoError(t, err)
+
+	did, err := didpkh.New(accountId)
+	require.NoError(t, err)
+	assert.Equal(t, "did:pkh:eip155:1:0xb9c5714089478a327f09197987f16f9e5d936e8a", did.String())
+
+	_, err = didpkh.New("cosmos:cosmoshub-3:cosmos1t2uflqwqe0fsj0shcfkrvpukewcw40yjj6hdc0")
+	assert.ErrorContains(t, err, "unsupported_namespace")
+
+	doc, _, _, err := didpkh.NewResolver().Resolve(context.Background(), did.String(), diddoc.ResolutionOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, did.String(), doc.Subject().String())
+}
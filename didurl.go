@@ -0,0 +1,179 @@
+// Copyright 2023 The Go SSI Framework Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+package diddoc
+
+import (
+	"errors"
+	"net/url"
+	"strings"
+	"time"
+)
+
+const (
+	serviceParam     string = "service"
+	relativeRefParam string = "relativeRef"
+	versionIdParam   string = "versionId"
+	versionTimeParam string = "versionTime"
+	hashlinkParam    string = "hl"
+)
+
+var (
+	errInvalidDIDURL error = errors.New("invalid_did_url")
+)
+
+// DIDURL is a DID URL as defined in DID Core 3.2 DID URL Syntax.
+// A relative DID URL (e.g. #key-1) has a zero DID until it is resolved against a base DID.
+type DIDURL struct {
+	DID DID
+	// Path is the path-abempty part, including the leading slash
+	Path string
+	// RawQuery is the encoded query without the leading question mark
+	RawQuery string
+	// Fragment is the fragment without the leading hash
+	Fragment string
+}
+
+// ParseDIDURL parses an absolute or a relative DID URL:
+//
+//	did-url = did path-abempty [ "?" query ] [ "#" fragment ]
+func ParseDIDURL(s string) (DIDURL, error) {
+	var u DIDURL
+
+	rest := s
+	if i := strings.IndexByte(rest, '#'); i >= 0 {
+		u.Fragment, rest = rest[i+1:], rest[:i]
+		if !isURIComponent(u.Fragment, "/?") {
+			return DIDURL{}, errInvalidDIDURL
+		}
+	}
+	if i := strings.IndexByte(rest, '?'); i >= 0 {
+		u.RawQuery, rest = rest[i+1:], rest[:i]
+		if !isURIComponent(u.RawQuery, "/?") {
+			return DIDURL{}, errInvalidDIDURL
+		}
+		if _, err := url.ParseQuery(u.RawQuery); err != nil {
+			return DIDURL{}, errInvalidDIDURL
+		}
+	}
+	if i := strings.IndexByte(rest, '/'); i >= 0 {
+		u.Path, rest = rest[i:], rest[:i]
+		if !isURIComponent(u.Path, "/") {
+			return DIDURL{}, errInvalidDIDURL
+		}
+	}
+	if rest != "" {
+		did, err := ParseDID(rest)
+		if err != nil {
+			return DIDURL{}, errInvalidDIDURL
+		}
+		u.DID = did
+	} else if u.Path == "" && u.RawQuery == "" && !strings.Contains(s, "#") {
+		return DIDURL{}, errInvalidDIDURL
+	}
+	return u, nil
+}
+
+// IsRelative reports whether the DID URL lacks a DID
+func (u DIDURL) IsRelative() bool {
+	return u.DID.IsZero()
+}
+
+// ResolveReference resolves a relative DID URL against the base DID, an absolute DID URL is returned as is
+func (u DIDURL) ResolveReference(base DID) DIDURL {
+	if !u.IsRelative() {
+		return u
+	}
+	resolved := u
+	resolved.DID = base
+	return resolved
+}
+
+// Query returns the parsed DID parameters of the DID URL
+func (u DIDURL) Query() url.Values {
+	values, _ := url.ParseQuery(u.RawQuery)
+	return values
+}
+
+// Service returns the service DID parameter, which identifies a service from the DID document by service id
+func (u DIDURL) Service() string {
+	return u.Query().Get(serviceParam)
+}
+
+// RelativeRef returns the relativeRef DID parameter, a relative URI reference to a resource at a service endpoint
+func (u DIDURL) RelativeRef() string {
+	return u.Query().Get(relativeRefParam)
+}
+
+// VersionId returns the versionId DID parameter, which identifies a specific version of a DID document
+func (u DIDURL) VersionId() string {
+	return u.Query().Get(versionIdParam)
+}
+
+// VersionTime returns the versionTime DID parameter, which identifies the DID document valid at that time
+func (u DIDURL) VersionTime() (time.Time, error) {
+	value := u.Query().Get(versionTimeParam)
+	if value == "" {
+		return time.Time{}, errNotFound
+	}
+	return time.Parse(time.RFC3339, value)
+}
+
+// HL returns the hl DID parameter, a hashlink of the resource for integrity protection
+func (u DIDURL) HL() string {
+	return u.Query().Get(hashlinkParam)
+}
+
+// String returns the DID URL in its string representation
+func (u DIDURL) String() string {
+	var sb strings.Builder
+	sb.WriteString(u.DID.String())
+	sb.WriteString(u.Path)
+	if u.RawQuery != "" {
+		sb.WriteString("?" + u.RawQuery)
+	}
+	if u.Fragment != "" {
+		sb.WriteString("#" + u.Fragment)
+	}
+	return sb.String()
+}
+
+// normalizeDIDURL resolves a (relative) DID URL against the base DID and strips the versionId and versionTime
+// parameters, which select a version of the document rather than another resource. The other parameters are kept.
+// Strings that are not DID URLs are returned unchanged.
+func normalizeDIDURL(s string, base DID) string {
+	u, err := ParseDIDURL(s)
+	if err != nil {
+		return s
+	}
+	u = u.ResolveReference(base)
+	var params []string
+	for _, param := range strings.Split(u.RawQuery, "&") {
+		name, _, _ := strings.Cut(param, "=")
+		if param != "" && name != versionIdParam && name != versionTimeParam {
+			params = append(params, param)
+		}
+	}
+	u.RawQuery = strings.Join(params, "&")
+	return u.String()
+}
+
+// isURIComponent reports whether the string only consists of pchar characters, and the extra characters
+func isURIComponent(s string, extra string) bool {
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		switch {
+		case isAlpha(c), isDigit(c), strings.IndexByte("-._~", c) >= 0:
+		case strings.IndexByte("!$&'()*+,;=", c) >= 0, c == ':', c == '@':
+		case strings.IndexByte(extra, c) >= 0:
+		case c == '%':
+			if i+2 >= len(s) || !isHexDigit(s[i+1]) || !isHexDigit(s[i+2]) {
+				return false
+			}
+			i += 2
+		default:
+			return false
+		}
+	}
+	return true
+}
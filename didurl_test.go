@@ -0,0 +1,98 @@
+// Copyright 2023 The Go SSI Framework Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+package diddoc_test
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/gossif/diddoc"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseDIDURL(t *testing.T) {
+	type errorTestCases struct {
+		description      string
+		input            string
+		expectedDID      string
+		expectedPath     string
+		expectedQuery    string
+		expectedFragment string
+		expectedError    string
+	}
+	for _, scenario := range []errorTestCases{
+		{description: "did only", input: "did:example:123", expectedDID: "did:example:123"},
+		{description: "fragment", input: "did:example:123#key-1", expectedDID: "did:example:123", expectedFragment: "key-1"},
+		{description: "path", input: "did:example:123/path/to/resource", expectedDID: "did:example:123", expectedPath: "/path/to/resource"},
+		{description: "query and fragment", input: "did:example:123?versionId=2#key-1", expectedDID: "did:example:123", expectedQuery: "versionId=2", expectedFragment: "key-1"},
+		{description: "all parts", input: "did:example:123/path?service=agent&relativeRef=%2Fcredentials#degree", expectedDID: "did:example:123", expectedPath: "/path", expectedQuery: "service=agent&relativeRef=%2Fcredentials", expectedFragment: "degree"},
+		{description: "relative fragment", input: "#key-1", expectedFragment: "key-1"},
+		{description: "relative query", input: "?service=files", expectedQuery: "service=files"},
+		{description: "invalid did", input: "did:Example:123#key-1", expectedError: "invalid_did_url"},
+		{description: "invalid fragment", input: "did:example:123#key 1", expectedError: "invalid_did_url"},
+		{description: "invalid query", input: "did:example:123?versionId=%zz", expectedError: "invalid_did_url"},
+		{description: "empty", input: "", expectedError: "invalid_did_url"},
+	} {
+		t.Run(scenario.description, func(t *testing.T) {
+
+			u, err := diddoc.ParseDIDURL(scenario.input)
+			if scenario.expectedError != "" {
+				assert.ErrorContains(t, err, scenario.expectedError)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, scenario.expectedDID, u.DID.String())
+			assert.Equal(t, scenario.expectedPath, u.Path)
+			assert.Equal(t, scenario.expectedQuery, u.RawQuery)
+			assert.Equal(t, scenario.expectedFragment, u.Fragment)
+			assert.Equal(t, scenario.expectedDID == "", u.IsRelative())
+			assert.Equal(t, scenario.input, u.String())
+		})
+	}
+}
+
+func TestDIDURLParameters(t *testing.T) {
+	u, err := diddoc.ParseDIDURL("did:example:123?service=files&relativeRef=%2Fresume.pdf&versionId=2&versionTime=2021-05-10T17:00:00Z&hl=zQmWvQxTqbG2Z9HPJgG57jjwR154cKhbtJenbyYTWkjgF3e")
+	require.NoError(t, err)
+
+	assert.Equal(t, "files", u.Service())
+	assert.Equal(t, "/resume.pdf", u.RelativeRef())
+	assert.Equal(t, "2", u.VersionId())
+	assert.Equal(t, "zQmWvQxTqbG2Z9HPJgG57jjwR154cKhbtJenbyYTWkjgF3e", u.HL())
+
+	versionTime, err := u.VersionTime()
+	assert.NoError(t, err)
+	assert.Equal(t, time.Date(2021, 5, 10, 17, 0, 0, 0, time.UTC), versionTime)
+}
+
+func TestResolveReference(t *testing.T) {
+	base := diddoc.MustParseDID("did:example:123")
+
+	relative, err := diddoc.ParseDIDURL("#key-1")
+	require.NoError(t, err)
+	assert.Equal(t, "did:example:123#key-1", relative.ResolveReference(base).String())
+
+	absolute, err := diddoc.ParseDIDURL("did:example:456#key-1")
+	require.NoError(t, err)
+	assert.Equal(t, "did:example:456#key-1", absolute.ResolveReference(base).String())
+}
+
+func TestGetVerificationMethodByDIDURL(t *testing.T) {
+	inputBytes := []byte(`{"@context":["https://www.w3.org/ns/did/v1"],"id":"did:example:123","verificationMethod":[{"id":"#key-1","type":"JsonWebKey2020","controller":"did:example:123","publicKeyJwk":{"kty":"OKP","crv":"X25519","x":"pE_mG098rdQjY3MKK2D5SUQ6ZOEW3a6Z6T7Z4SgnzCE"}}]}`)
+
+	doc := diddoc.NewDocument()
+	require.NoError(t, json.Unmarshal(inputBytes, doc))
+
+	for _, keyId := range []string{"#key-1", "did:example:123#key-1", "did:example:123?versionId=2#key-1"} {
+		t.Run(keyId, func(t *testing.T) {
+			verificationMethod, err := doc.GetVerificationMethodById(keyId)
+			assert.NoError(t, err)
+			assert.Equal(t, "#key-1", verificationMethod.Id)
+		})
+	}
+	_, err := doc.GetVerificationMethodById("did:example:456#key-1")
+	assert.ErrorContains(t, err, "not_found")
+}
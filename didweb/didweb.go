@@ -0,0 +1,184 @@
+// Copyright 2023 The Go SSI Framework Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package didweb implements the did:web method, which resolves a DID document hosted on a web server.
+package didweb
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"github.com/gossif/diddoc"
+)
+
+// Method is the DID method name
+const Method string = "web"
+
+const (
+	wellKnownPath string = ".well-known"
+	documentName  string = "did.json"
+
+	// maxDocumentSize limits the size of a fetched DID document
+	maxDocumentSize int64 = 1 << 20
+)
+
+var (
+	errIdMismatch error = errors.New("id_mismatch")
+)
+
+// Path returns the path of the DID document on the web server, without the leading slash:
+//
+//	did:web:example.com            -> .well-known/did.json
+//	did:web:example.com:user:alice -> user/alice/did.json
+func Path(did string) (string, error) {
+	_, path, err := split(did)
+	return path, err
+}
+
+// URL returns the HTTPS URL of the DID document
+func URL(did string) (string, error) {
+	host, path, err := split(did)
+	if err != nil {
+		return "", err
+	}
+	return "https://" + host + "/" + path, nil
+}
+
+// split splits the did:web into the host, including an optional port, and the path of the DID document
+func split(did string) (string, string, error) {
+	parsed, err := diddoc.ParseDID(did)
+	if err != nil {
+		return "", "", fmt.Errorf("%w: %v", diddoc.InvalidDid, err)
+	}
+	if parsed.Method != Method {
+		return "", "", fmt.Errorf("%w: method %q is not %q", diddoc.InvalidDid, parsed.Method, Method)
+	}
+	host, err := parseHost(parsed.Segments[0])
+	if err != nil {
+		return "", "", err
+	}
+	segments := parsed.Segments[1:]
+	for _, segment := range segments {
+		// only the port colon of the host may be percent-encoded, a decoded path segment could escape the path
+		if segment == "" || segment == "." || segment == ".." || strings.Contains(segment, "%") {
+			return "", "", fmt.Errorf("%w: invalid segment %q", diddoc.InvalidDid, segment)
+		}
+	}
+	if len(segments) == 0 {
+		return host, wellKnownPath + "/" + documentName, nil
+	}
+	return host, strings.Join(segments, "/") + "/" + documentName, nil
+}
+
+// parseHost parses the first segment of the did:web as a host with an optional percent-encoded port,
+// anything that an URL would not treat as a bare host, such as userinfo, a query or a fragment, is rejected
+func parseHost(segment string) (string, error) {
+	host := segment
+	if i := strings.Index(strings.ToUpper(host), "%3A"); i >= 0 {
+		host = host[:i] + ":" + host[i+3:]
+	}
+	if host == "" || strings.ContainsAny(host, "%@?#\\/") {
+		return "", fmt.Errorf("%w: invalid host %q", diddoc.InvalidDid, segment)
+	}
+	u, err := url.Parse("https://" + host)
+	if err != nil || u.Host != host || u.User != nil || u.Path != "" || u.RawQuery != "" || u.Fragment != "" || u.Hostname() == "" {
+		return "", fmt.Errorf("%w: invalid host %q", diddoc.InvalidDid, segment)
+	}
+	if port := u.Port(); port != "" || strings.HasSuffix(host, ":") {
+		if _, err := strconv.ParseUint(port, 10, 16); err != nil {
+			return "", fmt.Errorf("%w: invalid port %q", diddoc.InvalidDid, segment)
+		}
+	}
+	return host, nil
+}
+
+// Publish returns the path and the JSON representation of the DID document to host on the web server
+func Publish(doc *diddoc.Document) (string, []byte, error) {
+	path, err := Path(doc.Subject().String())
+	if err != nil {
+		return "", nil, err
+	}
+	data, err := json.Marshal(doc)
+	if err != nil {
+		return "", nil, err
+	}
+	return path, data, nil
+}
+
+type options struct {
+	client *http.Client
+}
+
+// Option configures the did:web resolver
+type Option func(*options)
+
+// WithHTTPClient sets the HTTP client that fetches the DID documents
+func WithHTTPClient(client *http.Client) Option {
+	return func(o *options) {
+		o.client = client
+	}
+}
+
+// Resolver resolves did:web DIDs, it can be registered with a diddoc.Registry
+type Resolver struct {
+	client *http.Client
+}
+
+// NewResolver creates a did:web resolver, which uses the http.DefaultClient unless configured otherwise
+func NewResolver(opts ...Option) *Resolver {
+	o := options{client: http.DefaultClient}
+	for _, opt := range opts {
+		opt(&o)
+	}
+	return &Resolver{client: o.client}
+}
+
+// Resolve fetches the DID document from the web server and verifies that its id matches the DID
+func (r *Resolver) Resolve(ctx context.Context, did string, opts diddoc.ResolutionOptions) (*diddoc.Document, diddoc.ResolutionMetadata, diddoc.DocumentMetadata, error) {
+	documentURL, err := URL(did)
+	if err != nil {
+		return resolutionFailed(diddoc.InvalidDid, err)
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, documentURL, nil)
+	if err != nil {
+		return resolutionFailed(diddoc.InternalError, err)
+	}
+	req.Header.Set("Accept", diddoc.ContentTypeDIDJSON+", application/json")
+
+	resp, err := r.client.Do(req)
+	if err != nil {
+		return resolutionFailed(diddoc.InternalError, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return resolutionFailed(diddoc.NotFound, fmt.Errorf("%w: %s returned %s", diddoc.NotFound, documentURL, resp.Status))
+	}
+	data, err := io.ReadAll(io.LimitReader(resp.Body, maxDocumentSize))
+	if err != nil {
+		return resolutionFailed(diddoc.InternalError, err)
+	}
+	doc := diddoc.NewDocument()
+	if err := doc.UnmarshalJSON(data); err != nil {
+		return resolutionFailed(diddoc.InvalidDidDocument, err)
+	}
+	if doc.Subject().String() != did {
+		return resolutionFailed(diddoc.InvalidDidDocument, errIdMismatch)
+	}
+	return doc, diddoc.ResolutionMetadata{ContentType: diddoc.ContentTypeDIDJSON}, diddoc.DocumentMetadata{}, nil
+}
+
+func resolutionFailed(code diddoc.ResolutionError, err error) (*diddoc.Document, diddoc.ResolutionMetadata, diddoc.DocumentMetadata, error) {
+	if !errors.Is(err, code) {
+		err = fmt.Errorf("%w: %v", code, err)
+	}
+	return nil, diddoc.ResolutionMetadata{Error: code}, diddoc.DocumentMetadata{}, err
+}
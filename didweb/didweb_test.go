@@ -0,0 +1,116 @@
+// Copyright 2023 The Go SSI Framework Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+package didweb_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gossif/diddoc"
+	"github.com/gossif/diddoc/didweb"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestURL(t *testing.T) {
+	type errorTestCases struct {
+		description    string
+		input          string
+		expectedOutput string
+		expectedError  string
+	}
+	for _, scenario := range []errorTestCases{
+		{description: "domain", input: "did:web:w3c-ccg.github.io", expectedOutput: "https://w3c-ccg.github.io/.well-known/did.json"},
+		{description: "path", input: "did:web:w3c-ccg.github.io:user:alice", expectedOutput: "https://w3c-ccg.github.io/user/alice/did.json"},
+		{description: "port", input: "did:web:example.com%3A3000:user:alice", expectedOutput: "https://example.com:3000/user/alice/did.json"},
+		{description: "other method", input: "did:example:123", expectedError: "invalidDid"},
+		{description: "empty segment", input: "did:web:example.com::alice", expectedError: "invalidDid"},
+		{description: "encoded slash", input: "did:web:example.com:user%2Falice", expectedError: "invalidDid"},
+		{description: "userinfo", input: "did:web:example.com%40evil.com", expectedError: "invalidDid"},
+		{description: "query", input: "did:web:example.com%3Fx=", expectedError: "invalidDid"},
+		{description: "fragment", input: "did:web:example.com%23x", expectedError: "invalidDid"},
+		{description: "backslash", input: "did:web:example.com%5Cevil.com", expectedError: "invalidDid"},
+		{description: "invalid port", input: "did:web:example.com%3Ahttp", expectedError: "invalidDid"},
+		{description: "empty port", input: "did:web:example.com%3A", expectedError: "invalidDid"},
+		{description: "dot segment", input: "did:web:example.com:.:alice", expectedError: "invalidDid"},
+		{description: "dot dot segments", input: "did:web:example.com:..:..:admin", expectedError: "invalidDid"},
+		{description: "encoded path segment", input: "did:web:example.com:user%3Falice", expectedError: "invalidDid"},
+	} {
+		t.Run(scenario.description, func(t *testing.T) {
+
+			actualOutput, err := didweb.URL(scenario.input)
+			if scenario.expectedError != "" {
+				assert.ErrorContains(t, err, scenario.expectedError)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, scenario.expectedOutput, actualOutput)
+		})
+	}
+}
+
+func TestResolve(t *testing.T) {
+	var documents map[string][]byte
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		data, ok := documents[r.URL.Path]
+		if !ok {
+			http.NotFound(w, r)
+			return
+		}
+		w.Header().Set("Content-Type", diddoc.ContentTypeDIDJSON)
+		w.Write(data)
+	}))
+	defer server.Close()
+
+	host := strings.ReplaceAll(strings.TrimPrefix(server.URL, "https://"), ":", "%3A")
+	alice := "did:web:" + host + ":user:alice"
+	bob := "did:web:" + host + ":user:bob"
+
+	doc, err := diddoc.NewBuilder().Context(diddoc.ContextDIDv1).Subject(alice).Build()
+	require.NoError(t, err)
+	path, data, err := didweb.Publish(doc)
+	require.NoError(t, err)
+	assert.Equal(t, "user/alice/did.json", path)
+
+	documents = map[string][]byte{
+		"/" + path:              data,
+		"/user/bob/did.json":    data,
+		"/.well-known/did.json": []byte(`not a did document`),
+	}
+	resolver := didweb.NewResolver(didweb.WithHTTPClient(server.Client()))
+
+	type errorTestCases struct {
+		description   string
+		input         string
+		expectedError diddoc.ResolutionError
+	}
+	for _, scenario := range []errorTestCases{
+		{description: "found", input: alice},
+		{description: "not found", input: "did:web:" + host + ":user:carol", expectedError: diddoc.NotFound},
+		{description: "id mismatch", input: bob, expectedError: diddoc.InvalidDidDocument},
+		{description: "invalid document", input: "did:web:" + host, expectedError: diddoc.InvalidDidDocument},
+	} {
+		t.Run(scenario.description, func(t *testing.T) {
+
+			actualDoc, resolutionMetadata, _, err := resolver.Resolve(context.Background(), scenario.input, diddoc.ResolutionOptions{})
+			if scenario.expectedError != "" {
+				assert.ErrorIs(t, err, scenario.expectedError)
+				assert.Equal(t, scenario.expectedError, resolutionMetadata.Error)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, scenario.input, actualDoc.Subject().String())
+			assert.Equal(t, diddoc.ContentTypeDIDJSON, resolutionMetadata.ContentType)
+		})
+	}
+	t.Run("transport error", func(t *testing.T) {
+		// the default client does not trust the certificate of the test server
+		_, resolutionMetadata, _, err := didweb.NewResolver().Resolve(context.Background(), alice, diddoc.ResolutionOptions{})
+		assert.ErrorIs(t, err, diddoc.InternalError)
+		assert.Equal(t, diddoc.InternalError, resolutionMetadata.Error)
+	})
+}
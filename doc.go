@@ -4,8 +4,11 @@
 package diddoc
 
 import (
+	"bytes"
 	"encoding/json"
 	"errors"
+	"fmt"
+	"io"
 	"reflect"
 	"sync"
 )
@@ -22,11 +25,16 @@ const (
 	capabilityInvocationKey string = "capabilityInvocation"
 	capabilityDelegationKey string = "capabilityDelegation"
 	serviceKey              string = "service"
+
+	didDocumentKey           string = "didDocument"
+	didDocumentMetadataKey   string = "didDocumentMetadata"
+	didResolutionMetadataKey string = "didResolutionMetadata"
 )
 
 var (
 	errNotFound    error = errors.New("not_found")
 	errInvalidType error = errors.New("invalid_type_conversion")
+	errReadOnly    error = errors.New("read_only_document")
 )
 
 // MapItem representation of one map item.
@@ -37,10 +45,18 @@ type MapItem struct {
 // MapSlice of map items.
 type MapSlice []MapItem
 
-// didDocument holds the properties, the metadsata, and options for document resolution
+// didDocument holds the properties, the metadata, and options for document resolution.
+// A document is safe for concurrent use when created by NewDocument or the builder, it must not be
+// copied by value as the copies share their state, use Clone for an independent copy instead. The values
+// that are returned by a document must not be modified, except those of a read-only snapshot, which are copies.
 type Document struct {
 	mu         *sync.RWMutex
 	properties MapSlice
+	metadata   DocumentMetadata
+	// readOnly is set for a snapshot, which rejects all mutations
+	readOnly bool
+	// scalars are the properties that the builder received as a single value and turned into a set
+	scalars map[string]bool
 }
 
 // NewDocument creates a document instance
@@ -56,9 +72,22 @@ func (d *Document) Context() interface{} {
 	return d.Get(contextKey)
 }
 
-// Subject gets the did subject property of the document
-func (d *Document) Subject() interface{} {
-	return d.Get(subjectKey)
+// Subject gets the did subject property of the document, the zero DID is returned when absent or malformed
+func (d *Document) Subject() DID {
+	defer d.rlock()()
+	return d.subject()
+}
+
+func (d *Document) subject() DID {
+	var subject string
+	if err := encode(&subject, d.get(subjectKey)); err != nil {
+		return DID{}
+	}
+	did, err := ParseDID(subject)
+	if err != nil {
+		return DID{}
+	}
+	return did
 }
 
 // AlsoKnownAs gets the alsoKnownAs property of the document
@@ -108,13 +137,41 @@ func (d *Document) Services() interface{} {
 
 // Metadata gets the metadata of the document
 func (d *Document) Metadata() DocumentMetadata {
-	return DocumentMetadata{
-		Deactivated: false,
+	defer d.rlock()()
+	if d.readOnly {
+		return d.metadata.clone()
 	}
+	return d.metadata
+}
+
+// SetMetadata sets the metadata of the document, typically by the resolver of the document. The metadata of a
+// snapshot is rejected with read_only_document.
+func (d *Document) SetMetadata(metadata DocumentMetadata) error {
+	defer d.lock()()
+	if d.readOnly {
+		return errReadOnly
+	}
+	d.metadata = metadata
+	return nil
+}
+
+// ReadOnly reports whether the document is a snapshot, which rejects all mutations
+func (d *Document) ReadOnly() bool {
+	defer d.rlock()()
+	return d.readOnly
 }
 
-// Get gets the value of the property with a key
+// Get gets the value of the property with a key. The value of a read-only document is a copy, so that a
+// snapshot can not be changed through it.
 func (d *Document) Get(key string) interface{} {
+	defer d.rlock()()
+	if d.readOnly {
+		return copyValue(d.get(key))
+	}
+	return d.get(key)
+}
+
+func (d *Document) get(key string) interface{} {
 	for _, prop := range d.properties {
 		if prop.Key == key {
 			return prop.Value
@@ -123,22 +180,63 @@ func (d *Document) Get(key string) interface{} {
 	return nil
 }
 
-// Set sets the value of the property with a key
+// Set sets the value of the property with a key. The value of an existing property is replaced, and the property
+// keeps its position.
 func (d *Document) Set(key, value interface{}) error {
-	d.mu.Lock()
-	defer d.mu.Unlock()
-
-	property := MapItem{Key: key, Value: value}
-	d.properties = append(d.properties, property)
+	defer d.lock()()
+	if d.readOnly {
+		return errReadOnly
+	}
+	d.set(key, value)
 	return nil
 }
 
-// GetAssociatedVerificationMethod gets the associated verification method for a purpose
+// set sets the value of the first property with the key, or adds the property when absent
+func (d *Document) set(key, value interface{}) {
+	if k, ok := key.(string); ok {
+		delete(d.scalars, k)
+	}
+	for i, prop := range d.properties {
+		if prop.Key == key {
+			d.properties[i].Value = value
+			return
+		}
+	}
+	d.properties = append(d.properties, MapItem{Key: key, Value: value})
+}
+
+// rlock locks the document for reading and returns the unlock function. A zero Document has no
+// mutex, it is only safe for use by one goroutine.
+func (d *Document) rlock() func() {
+	if d.mu == nil {
+		return func() {}
+	}
+	d.mu.RLock()
+	return d.mu.RUnlock
+}
+
+// lock locks the document for writing and returns the unlock function, a zero Document is not locked like
+// with rlock
+func (d *Document) lock() func() {
+	if d.mu == nil {
+		return func() {}
+	}
+	d.mu.Lock()
+	return d.mu.Unlock
+}
+
+// GetAssociatedVerificationMethod gets the associated verification method for a purpose, references that do not
+// resolve are skipped. LookupVerificationMethods reports them, and tells embedded from referenced methods.
 func (d *Document) GetAssociatedVerificationMethod(purpose ProofPurpose) ([]VerificationMethod, error) {
+	defer d.rlock()()
+	return d.getAssociatedVerificationMethod(purpose)
+}
+
+func (d *Document) getAssociatedVerificationMethod(purpose ProofPurpose) ([]VerificationMethod, error) {
 	var response []VerificationMethod
 	responseValue := reflect.ValueOf(&response).Elem()
 
-	verificationRelation := d.Get(purpose.String())
+	verificationRelation := d.get(purpose.String())
 	if verificationRelation != nil {
 		v := reflect.ValueOf(verificationRelation)
 
@@ -164,18 +262,28 @@ func (d *Document) GetAssociatedVerificationMethod(purpose ProofPurpose) ([]Veri
 	return []VerificationMethod{}, errNotFound
 }
 
+// GetVerificationMethodById gets the verification method by its id. The id is a (relative) DID URL,
+// which is resolved against the document subject, so #key-1 and did:example:123#key-1 are equivalent.
 func (d *Document) GetVerificationMethodById(keyId string) (VerificationMethod, error) {
+	defer d.rlock()()
+	return d.getVerificationMethodById(keyId)
+}
+
+func (d *Document) getVerificationMethodById(keyId string) (VerificationMethod, error) {
 	var response VerificationMethod
 	responseValue := reflect.ValueOf(&response).Elem()
 
-	verificationMehods := d.Get(verificationMethodKey)
+	subject := d.subject()
+	keyId = normalizeDIDURL(keyId, subject)
+
+	verificationMehods := d.get(verificationMethodKey)
 	if verificationMehods != nil {
 		v := reflect.ValueOf(verificationMehods)
 
 		switch v.Kind() {
 		case reflect.Slice, reflect.Array:
 			for i := 0; i < v.Len(); i++ {
-				if d.verificationMethodFound(v.Index(i), keyId, responseValue) {
+				if d.verificationMethodFound(v.Index(i), keyId, subject, responseValue) {
 
 					switch resp := responseValue.Interface().(type) {
 					case VerificationMethod:
@@ -190,15 +298,15 @@ func (d *Document) GetVerificationMethodById(keyId string) (VerificationMethod,
 	return VerificationMethod{}, errNotFound
 }
 
-func (d *Document) verificationMethodFound(iteratorValue reflect.Value, keyId string, foundValue reflect.Value) bool {
+func (d *Document) verificationMethodFound(iteratorValue reflect.Value, keyId string, subject DID, foundValue reflect.Value) bool {
 	switch iteratorValue.Kind() {
 	case reflect.Struct:
-		if iteratorValue.FieldByName("Id").String() == keyId {
+		if normalizeDIDURL(iteratorValue.FieldByName("Id").String(), subject) == keyId {
 			foundValue.Set(iteratorValue)
 			return true
 		}
 	case reflect.Interface:
-		return d.verificationMethodFound(iteratorValue.Elem(), keyId, foundValue)
+		return d.verificationMethodFound(iteratorValue.Elem(), keyId, subject, foundValue)
 	}
 	return false
 }
@@ -206,17 +314,14 @@ func (d *Document) verificationMethodFound(iteratorValue reflect.Value, keyId st
 func (d *Document) addVerificationMethod(iteratorValue reflect.Value, foundValue reflect.Value) error {
 	switch iteratorValue.Kind() {
 	case reflect.Map:
-		var verificatiobMethod VerificationMethod
-		verificationMethodValue := reflect.ValueOf(&verificatiobMethod).Elem()
-		verificationMethodType := reflect.TypeOf(&verificatiobMethod).Elem()
-
-		if err := structEncoder(verificationMethodValue, verificationMethodType, iteratorValue); err != nil {
+		var verificationMethod VerificationMethod
+		if err := encode(&verificationMethod, iteratorValue.Interface()); err != nil {
 			return err
 		}
-		foundValue.Set(reflect.Append(foundValue, verificationMethodValue))
+		foundValue.Set(reflect.Append(foundValue, reflect.ValueOf(verificationMethod)))
 
 	case reflect.String:
-		verificationMethod, err := d.GetVerificationMethodById(iteratorValue.String())
+		verificationMethod, err := d.getVerificationMethodById(iteratorValue.String())
 		if err != nil {
 			return nil //no error, verificatiod might be revoked
 		}
@@ -233,43 +338,108 @@ func (d *Document) addVerificationMethod(iteratorValue reflect.Value, foundValue
 	return nil
 }
 
+// MarshalJSON encodes the properties in the order in which they were set, including the custom
+// properties, so that the same document always results in the same bytes
 func (d *Document) MarshalJSON() ([]byte, error) {
-	mapKeyValue := map[string]interface{}{}
+	defer d.rlock()()
+
+	var buf bytes.Buffer
+	buf.WriteByte('{')
+	written := map[string]bool{}
 	for _, prop := range d.properties {
-		switch prop.Key.(string) {
-		case subjectKey:
-			mapKeyValue[prop.Key.(string)] = prop.Value
+		key, ok := prop.Key.(string)
+		// the first occurrence of a key is the value of the property, see Get
+		if !ok || written[key] {
+			continue
+		}
+		value := prop.Value
+		switch key {
 		case contextKey, controllerKey:
-			value := prop.Value.([]string)
-			if len(value) > 1 {
-				mapKeyValue[prop.Key.(string)] = prop.Value
-			} else {
-				mapKeyValue[prop.Key.(string)] = value[0]
+			if values, ok := value.([]string); ok && len(values) == 1 {
+				value = values[0]
 			}
-		case alsoKnownAsKey:
-			mapKeyValue[prop.Key.(string)] = prop.Value
-		case verificationMethodKey:
-			mapKeyValue[prop.Key.(string)] = prop.Value
-		case authenticationKey, assertionMethodKey, keyAgreementKey, capabilityInvocationKey, capabilityDelegationKey:
-			mapKeyValue[prop.Key.(string)] = prop.Value
-		case serviceKey:
-			mapKeyValue[prop.Key.(string)] = prop.Value
 		}
+		keyBytes, err := json.Marshal(key)
+		if err != nil {
+			return nil, err
+		}
+		valueBytes, err := json.Marshal(value)
+		if err != nil {
+			return nil, fmt.Errorf("failed to marshal property %q: %w", key, err)
+		}
+		if len(written) > 0 {
+			buf.WriteByte(',')
+		}
+		buf.Write(keyBytes)
+		buf.WriteByte(':')
+		buf.Write(valueBytes)
+		written[key] = true
 	}
-	return json.Marshal(mapKeyValue)
+	buf.WriteByte('}')
+	return buf.Bytes(), nil
 }
 
+// UnmarshalJSON decodes a DID document, or a DID resolution result in which case the
+// didDocument is decoded and the didDocumentMetadata is carried as the document metadata
 func (d *Document) UnmarshalJSON(data []byte) error {
-	properties := map[string]interface{}{}
-	err := json.Unmarshal(data, &properties)
+	properties, err := decodeProperties(data)
+	if err != nil {
+		return err
+	}
+	for _, prop := range properties {
+		if prop.Key == didDocumentKey {
+			return d.unmarshalResolutionResult(data)
+		}
+	}
+	doc, err := buildDocument(properties)
 	if err != nil {
 		return err
 	}
+	defer d.lock()()
+	if d.readOnly {
+		return errReadOnly
+	}
+	d.properties = doc.properties
+	d.scalars = doc.scalars
+	return nil
+}
+
+func (d *Document) unmarshalResolutionResult(data []byte) error {
+	var result struct {
+		Document         json.RawMessage  `json:"didDocument"`
+		DocumentMetadata DocumentMetadata `json:"didDocumentMetadata"`
+	}
+	if err := json.Unmarshal(data, &result); err != nil {
+		return err
+	}
+	properties, err := decodeProperties(result.Document)
+	if err != nil {
+		return err
+	}
+	doc, err := buildDocument(properties)
+	if err != nil {
+		return err
+	}
+	defer d.lock()()
+	if d.readOnly {
+		return errReadOnly
+	}
+	d.properties, d.scalars, d.metadata = doc.properties, doc.scalars, result.DocumentMetadata
+	return nil
+}
+
+// buildDocument builds a document of the decoded properties, which records the properties that were a single value
+func buildDocument(properties MapSlice) (*Document, error) {
 	b := NewBuilder()
-	for key, value := range properties {
+	for _, prop := range properties {
+		key, value := prop.Key.(string), prop.Value
 		switch key {
-		case contextKey, alsoKnownAsKey, controllerKey:
+		case contextKey:
+			b.Context(value)
+		case alsoKnownAsKey:
 			b.stringArray(key, value)
+		case controllerKey:
+			b.Controller(value)
 		case subjectKey:
 			b.Subject(value)
 		case verificationMethodKey:
@@ -286,10 +456,34 @@ func (d *Document) UnmarshalJSON(data []byte) error {
 			b.CustomProperty(key, value)
 		}
 	}
-	doc, err := b.Build()
-	if err != nil {
-		return err
+	return b.Build()
+}
+
+// decodeProperties decodes a JSON object into properties, preserving the order of the members
+func decodeProperties(data []byte) (MapSlice, error) {
+	dec := json.NewDecoder(bytes.NewReader(data))
+	if tok, err := dec.Token(); err != nil {
+		return nil, err
+	} else if tok != json.Delim('{') {
+		return nil, errInvalidType
 	}
-	d.properties = doc.properties
-	return nil
+	var properties MapSlice
+	for dec.More() {
+		tok, err := dec.Token()
+		if err != nil {
+			return nil, err
+		}
+		var value interface{}
+		if err := dec.Decode(&value); err != nil {
+			return nil, err
+		}
+		properties = append(properties, MapItem{Key: tok.(string), Value: value})
+	}
+	if _, err := dec.Token(); err != nil {
+		return nil, err
+	}
+	if _, err := dec.Token(); err != io.EOF {
+		return nil, errInvalidType
+	}
+	return properties, nil
 }
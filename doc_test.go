@@ -6,9 +6,12 @@ package diddoc_test
 import (
 	"encoding/json"
 	"reflect"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/gossif/diddoc"
+	"github.com/lestrrat-go/jwx/v2/jwk"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
@@ -34,7 +37,7 @@ func testUnmarshal(t *testing.T) {
 	err := json.Unmarshal(expectedBytes, doc)
 	require.NoError(t, err)
 	assert.Equal(t, expectedContext, doc.Context())
-	assert.Equal(t, expectedId, doc.Subject())
+	assert.Equal(t, expectedId, doc.Subject().String())
 	assert.Equal(t, expectedControllerIdentifier, doc.Controller())
 }
 
@@ -100,17 +103,15 @@ func tstVerificationRelationWithEmbedded(t *testing.T) {
 
 	doc := diddoc.NewDocument()
 	require.NoError(t, json.Unmarshal(inputBytes, doc))
+	x25519JWK, err := jwk.ParseKey([]byte(`{"kty":"OKP","crv":"X25519","x":"pE_mG098rdQjY3MKK2D5SUQ6ZOEW3a6Z6T7Z4SgnzCE"}`))
+	require.NoError(t, err)
 
 	expectedOutput := []diddoc.VerificationMethod{
 		{
-			Controller: "did:example:123",
-			Id:         "did:example:123#key-1",
-			Type:       "JsonWebKey2020",
-			PubicKeyJWK: map[string]interface{}{
-				"kty": "OKP",
-				"crv": "X25519",
-				"x":   "pE_mG098rdQjY3MKK2D5SUQ6ZOEW3a6Z6T7Z4SgnzCE",
-			},
+			Controller:  "did:example:123",
+			Id:          "did:example:123#key-1",
+			Type:        "JsonWebKey2020",
+			PubicKeyJWK: x25519JWK,
 		},
 		{
 			Controller:         "did:example:123456789abcdefghi",
@@ -132,6 +133,8 @@ func TestGetVerificationMethodById(t *testing.T) {
 
 	doc := diddoc.NewDocument()
 	require.NoError(t, json.Unmarshal(inputBytes, doc))
+	x25519JWK, err := jwk.ParseKey([]byte(`{"kty":"OKP","crv":"X25519","x":"pE_mG098rdQjY3MKK2D5SUQ6ZOEW3a6Z6T7Z4SgnzCE"}`))
+	require.NoError(t, err)
 
 	type errorTestCases struct {
 		description    string
@@ -141,7 +144,7 @@ func TestGetVerificationMethodById(t *testing.T) {
 	}
 	for _, scenario := range []errorTestCases{
 		{description: "found", input: "did:example:123#key-1",
-			expectedOutput: diddoc.VerificationMethod{Controller: "did:example:123", Id: "did:example:123#key-1", Type: "JsonWebKey2020", PubicKeyJWK: map[string]interface{}{"kty": "OKP", "crv": "X25519", "x": "pE_mG098rdQjY3MKK2D5SUQ6ZOEW3a6Z6T7Z4SgnzCE"}}, expectedError: ""},
+			expectedOutput: diddoc.VerificationMethod{Controller: "did:example:123", Id: "did:example:123#key-1", Type: "JsonWebKey2020", PubicKeyJWK: x25519JWK}, expectedError: ""},
 	} {
 		t.Run(scenario.description, func(t *testing.T) {
 
@@ -156,3 +159,92 @@ func TestGetVerificationMethodById(t *testing.T) {
 		})
 	}
 }
+
+func TestDocumentMetadata(t *testing.T) {
+	inputBytes := []byte(`{"didDocument":{"@context":"https://www.w3.org/ns/did/v1","id":"did:example:123","alsoKnownAs":"alice"},"didDocumentMetadata":{"created":"2019-03-23T06:35:22Z","updated":"2023-08-10T13:40:06Z","deactivated":true,"versionId":"2","nextVersionId":"3","nextUpdate":"2023-09-10T13:40:06Z","equivalentId":["did:example:456"],"canonicalId":"did:example:789"},"didResolutionMetadata":{"contentType":"application/did+ld+json"}}`)
+
+	doc := diddoc.NewDocument()
+	require.NoError(t, json.Unmarshal(inputBytes, doc))
+
+	expectedMetadata := diddoc.DocumentMetadata{
+		Created:       time.Date(2019, 3, 23, 6, 35, 22, 0, time.UTC),
+		Updated:       time.Date(2023, 8, 10, 13, 40, 6, 0, time.UTC),
+		Deactivated:   true,
+		NextUpdate:    time.Date(2023, 9, 10, 13, 40, 6, 0, time.UTC),
+		VersionId:     "2",
+		NextVersionId: "3",
+		EquivalentId:  []string{"did:example:456"},
+		CanonicalId:   "did:example:789",
+	}
+	assert.Equal(t, "did:example:123", doc.Subject().String())
+	assert.Equal(t, expectedMetadata, doc.Metadata())
+
+	// the single values of the didDocument are tracked as in a DID document
+	violations := doc.Validate(diddoc.ValidationOptions{})
+	require.Len(t, violations.Errors(), 1)
+	assert.Equal(t, "/alsoKnownAs", violations.Errors()[0].Pointer)
+
+	actualBytes, err := json.Marshal(diddoc.DocumentMetadata{Deactivated: true, VersionId: "2"})
+	assert.NoError(t, err)
+	assert.Equal(t, `{"deactivated":true,"versionId":"2"}`, string(actualBytes))
+}
+
+func TestMarshalOrder(t *testing.T) {
+	for scenario, fn := range map[string]func(t *testing.T){
+		"builder order":   testMarshalBuilderOrder,
+		"unmarshal order": testMarshalUnmarshalOrder,
+		"deterministic":   testMarshalDeterministic,
+		"trailing data":   testUnmarshalTrailingData,
+	} {
+		t.Run(scenario, func(t *testing.T) {
+			fn(t)
+		})
+	}
+}
+
+func testMarshalBuilderOrder(t *testing.T) {
+	doc, err := diddoc.NewBuilder().
+		Subject("did:example:123").
+		Context([]string{"https://www.w3.org/ns/did/v1"}).
+		CustomProperty("created", "2023-01-01T00:00:00Z").
+		Controller([]string{"did:example:456", "did:example:789"}).
+		Build()
+	require.NoError(t, err)
+
+	actualBytes, err := json.Marshal(&doc)
+	require.NoError(t, err)
+	assert.Equal(t, `{"id":"did:example:123","@context":"https://www.w3.org/ns/did/v1","created":"2023-01-01T00:00:00Z","controller":["did:example:456","did:example:789"]}`, string(actualBytes))
+}
+
+func testMarshalUnmarshalOrder(t *testing.T) {
+	expectedBytes := []byte(`{"id":"did:example:123","@context":["https://www.w3.org/ns/did/v1","https://w3id.org/security/multikey/v1"],"x-custom":{"b":1,"a":[true,false]},"service":[{"id":"#linked-domain","type":"LinkedDomains","serviceEndpoint":"https://bar.example.com"}],"alsoKnownAs":["https://example.com/alice"]}`)
+
+	doc := diddoc.NewDocument()
+	require.NoError(t, json.Unmarshal(expectedBytes, doc))
+
+	actualBytes, err := json.Marshal(doc)
+	require.NoError(t, err)
+	// nested maps of custom properties are encoded with sorted keys
+	assert.Equal(t, strings.Replace(string(expectedBytes), `{"b":1,"a":[true,false]}`, `{"a":[true,false],"b":1}`, 1), string(actualBytes))
+}
+
+func testMarshalDeterministic(t *testing.T) {
+	inputBytes := []byte(`{"id":"did:example:123","z":{"c":3,"b":2,"a":1},"y":[{"d":4,"c":3}],"x":"value"}`)
+
+	doc := diddoc.NewDocument()
+	require.NoError(t, json.Unmarshal(inputBytes, doc))
+
+	expectedBytes, err := json.Marshal(doc)
+	require.NoError(t, err)
+	for i := 0; i < 20; i++ {
+		actualBytes, err := json.Marshal(doc)
+		require.NoError(t, err)
+		assert.Equal(t, expectedBytes, actualBytes)
+	}
+}
+
+func testUnmarshalTrailingData(t *testing.T) {
+	doc := diddoc.NewDocument()
+	assert.Error(t, json.Unmarshal([]byte(`["did:example:123"]`), doc))
+	assert.Error(t, doc.UnmarshalJSON([]byte(`{"id":"did:example:123"} {}`)))
+}
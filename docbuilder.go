@@ -4,7 +4,10 @@
 package diddoc
 
 import (
+	"errors"
 	"fmt"
+	"reflect"
+	"strings"
 )
 
 // BuilderItem representation of one map item.
@@ -17,6 +20,96 @@ type BuilderSlice []BuilderItem
 
 type builder struct {
 	properties BuilderSlice
+	errs       []*PropertyError
+	// scalars are the properties that were received as a single value, see Document.Validate
+	scalars map[string]bool
+}
+
+// PropertyError is the error of an invalid property, the path locates the property in the document,
+// e.g. verificationMethod[2].publicKeyJwk
+type PropertyError struct {
+	Path string
+	Err  error
+}
+
+func (e *PropertyError) Error() string {
+	return fmt.Sprintf("invalid property %q: %v", e.Path, e.Err)
+}
+
+func (e *PropertyError) Unwrap() error {
+	return e.Err
+}
+
+// BuildError holds the errors of all the invalid properties that the builder has received
+type BuildError struct {
+	Errors []*PropertyError
+}
+
+func (e *BuildError) Error() string {
+	messages := make([]string, len(e.Errors))
+	for i, err := range e.Errors {
+		messages[i] = err.Error()
+	}
+	return strings.Join(messages, "; ")
+}
+
+// Is reports whether one of the property errors matches the target, as errors.Is only unwraps multiple errors
+// from Go 1.20 on
+func (e *BuildError) Is(target error) bool {
+	for _, err := range e.Errors {
+		if errors.Is(err, target) {
+			return true
+		}
+	}
+	return false
+}
+
+// As finds the first property error that matches the target, see Is
+func (e *BuildError) As(target interface{}) bool {
+	for _, err := range e.Errors {
+		if errors.As(err, target) {
+			return true
+		}
+	}
+	return false
+}
+
+// propertyError prefixes the path of the error with the path of the property
+func propertyError(path string, err error) *PropertyError {
+	if e, ok := err.(*PropertyError); ok {
+		if strings.HasPrefix(e.Path, "[") {
+			return &PropertyError{Path: path + e.Path, Err: e.Err}
+		}
+		return &PropertyError{Path: path + "." + e.Path, Err: e.Err}
+	}
+	return &PropertyError{Path: path, Err: err}
+}
+
+// elements returns the items of a set, a single value is a set of one item
+func elements(v interface{}) (items []interface{}, isSet bool) {
+	rv := reflect.ValueOf(v)
+	switch rv.Kind() {
+	case reflect.Invalid:
+		return nil, false
+	case reflect.Slice, reflect.Array:
+		// a byte slice is a string
+		if rv.Type().Elem().Kind() != reflect.Uint8 {
+			items = make([]interface{}, rv.Len())
+			for i := range items {
+				items[i] = rv.Index(i).Interface()
+			}
+			return items, true
+		}
+	}
+	return []interface{}{v}, false
+}
+
+// elementPath returns the path of the item of a property, the index is omitted for a single value
+func elementPath(key string, i int, isSet bool) string {
+	if !isSet {
+		return key
+	}
+	return fmt.Sprintf("%s[%d]", key, i)
 }
 
 func NewBuilder() *builder {
@@ -31,37 +124,99 @@ func (b *builder) property(key string, value interface{}) *builder {
 	return b
 }
 
+// verificationRelationArray sets the verification relationship, each item is a reference to a verification
+// method, or an embedded verification method
 func (b *builder) verificationRelationArray(key string, v interface{}) *builder {
 	var d []VerificationRelation
-	err := encode(&d, v)
-	if err != nil {
-		panic(err)
+	items, isSet := elements(v)
+	for i, item := range items {
+		var relation VerificationRelation
+		if err := encode(&relation, item); err != nil {
+			b.setError(elementPath(key, i, isSet), err)
+			continue
+		}
+		if err := validateVerificationRelation(relation); err != nil {
+			b.setError(elementPath(key, i, isSet), err)
+			continue
+		}
+		d = append(d, relation)
 	}
+	b.scalar(key, v)
 	return b.property(key, d)
 }
 
+func validateVerificationRelation(relation VerificationRelation) error {
+	switch value := relation.(type) {
+	case string:
+		_, err := ParseDIDURL(value)
+		return err
+	case VerificationMethod, map[string]interface{}:
+		var verificationMethod VerificationMethod
+		return encode(&verificationMethod, value)
+	}
+	return errInvalidType
+}
+
 func (b *builder) stringArray(key string, v interface{}) *builder {
-	var d []string
-	err := encode(&d, v)
-	if err != nil {
-		panic(err)
+	d, valid := b.stringElements(key, v)
+	if !valid {
+		return b
 	}
+	b.scalar(key, v)
 	return b.property(key, d)
 }
 
+// stringElements encodes the items of the property as strings, the error of an item is recorded at its path
+func (b *builder) stringElements(key string, v interface{}) ([]string, bool) {
+	var d []string
+	items, isSet := elements(v)
+	valid := true
+	for i, item := range items {
+		var s string
+		if err := encode(&s, item); err != nil {
+			b.setError(elementPath(key, i, isSet), err)
+			valid = false
+			continue
+		}
+		d = append(d, s)
+	}
+	return d, valid
+}
+
 // Context is used as JSON-LD Context.
 // The value of MUST be a string or a list containing any combination of strings and/or ordered maps.
 func (b *builder) Context(v interface{}) *builder {
-	return b.stringArray(contextKey, v)
+	b.scalar(contextKey, v)
+	var d []string
+	if err := encode(&d, v); err == nil {
+		return b.property(contextKey, d)
+	}
+	// the embedded contexts are kept as they are
+	items, isSet := elements(v)
+	valid := true
+	for i, item := range items {
+		switch item.(type) {
+		case string, map[string]interface{}:
+		default:
+			b.setError(elementPath(contextKey, i, isSet), errInvalidContext)
+			valid = false
+		}
+	}
+	if !valid {
+		return b
+	}
+	return b.property(contextKey, items)
 }
 
 // Subject is the DID for a particular DID subject.
 // The value of MUST be a string that conforms to the rules in 3.1 DID Syntax.
 func (b *builder) Subject(v interface{}) *builder {
 	var d string
-	err := encode(&d, v)
-	if err != nil {
-		panic(err)
+	if err := encode(&d, v); err != nil {
+		return b.setError(subjectKey, err)
+	}
+	if _, err := ParseDID(d); err != nil {
+		return b.setError(subjectKey, err)
 	}
 	return b.property(subjectKey, d)
 }
@@ -75,17 +230,38 @@ func (b *builder) AlsoKnownAs(v interface{}) *builder {
 // Controller is the DID controller, an entity that is authorized to make changes to a DID document.
 // The value MUST be a string or a set of strings that conform to the rules in 3.1 DID Syntax.
 func (b *builder) Controller(v interface{}) *builder {
-	return b.stringArray(controllerKey, v)
+	d, valid := b.stringElements(controllerKey, v)
+	if !valid {
+		return b
+	}
+	_, isSet := elements(v)
+	for i, controller := range d {
+		if _, err := ParseDID(controller); err != nil {
+			b.setError(elementPath(controllerKey, i, isSet), err)
+			valid = false
+		}
+	}
+	if !valid {
+		return b
+	}
+	b.scalar(controllerKey, v)
+	return b.property(controllerKey, d)
 }
 
 // VerificationMethods are cryptographic public keys, which can be used to authenticate or authorize interactions with the DID subject or associated parties.
 // The value MUST be a verification method or a set of verification methods.
 func (b *builder) VerificationMethod(v interface{}) *builder {
 	var d []VerificationMethod
-	err := encode(&d, v)
-	if err != nil {
-		panic(err)
+	items, isSet := elements(v)
+	for i, item := range items {
+		var verificationMethod VerificationMethod
+		if err := encode(&verificationMethod, item); err != nil {
+			b.setError(elementPath(verificationMethodKey, i, isSet), err)
+			continue
+		}
+		d = append(d, verificationMethod)
 	}
+	b.scalar(verificationMethodKey, v)
 	return b.property(verificationMethodKey, d)
 }
 
@@ -115,13 +291,24 @@ func (b *builder) CapabilityDelegation(v interface{}) *builder {
 	return b.verificationRelationArray(capabilityDelegationKey, v)
 }
 
+// VerificationRelationship sets the verification relationship of the proof purpose.
+func (b *builder) VerificationRelationship(purpose ProofPurpose, v interface{}) *builder {
+	return b.verificationRelationArray(purpose.String(), v)
+}
+
 // Service is used in a DID documents to express ways of communicating with the DID subject or associated entities.
 func (b *builder) Service(v interface{}) *builder {
 	var d []Service
-	err := encode(&d, v)
-	if err != nil {
-		panic(err)
+	items, isSet := elements(v)
+	for i, item := range items {
+		var service Service
+		if err := encode(&service, item); err != nil {
+			b.setError(elementPath(serviceKey, i, isSet), err)
+			continue
+		}
+		d = append(d, service)
 	}
+	b.scalar(serviceKey, v)
 	return b.property(serviceKey, d)
 }
 
@@ -129,15 +316,36 @@ func (b *builder) CustomProperty(key string, v interface{}) *builder {
 	return b.property(key, v)
 }
 
-// Build creates a new token based on the claims that the builder has received
-// so far. If a claim cannot be set, then the method returns a nil Token with
-// a en error as a second return value
-func (b *builder) Build() (Document, error) {
+// scalar records whether the property was received as a single value, which the builder turns into a set
+func (b *builder) scalar(key string, v interface{}) {
+	if _, isSet := elements(v); isSet || v == nil {
+		return
+	}
+	if b.scalars == nil {
+		b.scalars = map[string]bool{}
+	}
+	b.scalars[key] = true
+}
+
+// setError records the error of the property at the path, all errors are returned by Build
+func (b *builder) setError(path string, err error) *builder {
+	b.errs = append(b.errs, propertyError(path, err))
+	return b
+}
+
+// Build creates a new document based on the properties that the builder has received
+// so far. If a property is invalid, then the method returns a nil document with
+// a BuildError, which holds the errors of all invalid properties
+func (b *builder) Build() (*Document, error) {
 	doc := NewDocument()
+	if len(b.errs) > 0 {
+		return nil, &BuildError{Errors: b.errs}
+	}
 	for _, property := range b.properties {
 		if err := doc.Set(property.Key, property.Value); err != nil {
-			return *doc, fmt.Errorf("failed to set property %q: %w", property.Key, err)
+			return nil, fmt.Errorf("failed to set property %q: %w", property.Key, err)
 		}
 	}
-	return *doc, nil
+	doc.scalars = b.scalars
+	return doc, nil
 }
@@ -12,6 +12,7 @@ import (
 
 	"github.com/lestrrat-go/jwx/v2/jwk"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func TestArrayStringValue(t *testing.T) {
@@ -49,18 +50,48 @@ func TestStringValue(t *testing.T) {
 		expectedError  string
 	}
 	for _, scenario := range []errorTestCases{
-		{description: "string to string", inputValue: "stringvalue", expectedOutput: "stringvalue", expectedType: "string", expectedError: ""},
-		{description: "byte to string", inputValue: []byte("bytevalue"), expectedOutput: "bytevalue", expectedType: "string", expectedError: ""},
-		{description: "bool to string", inputValue: true, expectedOutput: "true", expectedType: "string", expectedError: ""},
+		{description: "string to string", inputValue: "did:example:123", expectedOutput: "did:example:123", expectedType: "string", expectedError: ""},
+		{description: "byte to string", inputValue: []byte("did:example:456"), expectedOutput: "did:example:456", expectedType: "string", expectedError: ""},
+		{description: "bool to string", inputValue: true, expectedOutput: nil, expectedType: "", expectedError: "invalid_did"},
 	} {
 		t.Run(scenario.description, func(t *testing.T) {
 
-			doc, _ := diddoc.NewBuilder().Subject(scenario.inputValue).Build()
-			actualOutput := doc.Subject()
+			doc, err := diddoc.NewBuilder().Subject(scenario.inputValue).Build()
+			if scenario.expectedError != "" {
+				assert.ErrorContains(t, err, scenario.expectedError)
+				return
+			}
+			assert.NoError(t, err)
+			actualOutput := doc.Get("id")
 
 			assert.EqualValues(t, scenario.expectedOutput, actualOutput)
 			assert.EqualValues(t, scenario.expectedType, reflect.TypeOf(actualOutput).String())
+			assert.EqualValues(t, scenario.expectedOutput, doc.Subject().String())
+		})
+	}
+}
 
+func TestControllerValue(t *testing.T) {
+	type errorTestCases struct {
+		description    string
+		inputValue     interface{}
+		expectedOutput interface{}
+		expectedError  string
+	}
+	for _, scenario := range []errorTestCases{
+		{description: "single did", inputValue: "did:example:123", expectedOutput: []string{"did:example:123"}, expectedError: ""},
+		{description: "set of dids", inputValue: []interface{}{"did:example:123", "did:web:example.com%3A8443"}, expectedOutput: []string{"did:example:123", "did:web:example.com%3A8443"}, expectedError: ""},
+		{description: "malformed did", inputValue: []string{"did:example:123", "https://example.com"}, expectedError: "invalid_did"},
+	} {
+		t.Run(scenario.description, func(t *testing.T) {
+
+			doc, err := diddoc.NewBuilder().Controller(scenario.inputValue).Build()
+			if scenario.expectedError != "" {
+				assert.ErrorContains(t, err, scenario.expectedError)
+				return
+			}
+			assert.NoError(t, err)
+			assert.EqualValues(t, scenario.expectedOutput, doc.Controller())
 		})
 	}
 }
@@ -69,6 +100,7 @@ func TestVerificationMethods(t *testing.T) {
 	for scenario, fn := range map[string]func(t *testing.T){
 		"verificationMethod struct": testVerificationMethodStruct,
 		"verificationMethod map":    testVerificationMethodMap,
+		"private key":               testVerificationMethodPrivateKey,
 	} {
 		t.Run(scenario, func(t *testing.T) {
 			fn(t)
@@ -95,8 +127,10 @@ func testVerificationMethodStruct(t *testing.T) {
 }
 
 func testVerificationMethodMap(t *testing.T) {
+	pubKeyBytes := []byte(`{"crv":"P-256","kid":"did:example:123#4d98ef1d2c5947a586b2226b200ade72","kty":"EC","x":"nAyQZC6WAvSqnttlft7YOJrqmJx47t3-6l97XQfAGlU","y":"OWcile-qNKOsmXUsUDdYTwn39lvA_Qiml5gFMGaFraQ"}`)
 	var pubKey map[string]interface{}
-	json.Unmarshal([]byte(`{"crv":"P-256","kid":"did:example:123#4d98ef1d2c5947a586b2226b200ade72","kty":"EC","x":"nAyQZC6WAvSqnttlft7YOJrqmJx47t3-6l97XQfAGlU","y":"OWcile-qNKOsmXUsUDdYTwn39lvA_Qiml5gFMGaFraQ"}`), &pubKey)
+	json.Unmarshal(pubKeyBytes, &pubKey)
+	expectedKey, _ := jwk.ParseKey(pubKeyBytes)
 
 	input := map[string]interface{}{
 		"id":           "did:example:123#4d98ef1d2c5947a586b2226b200ade72",
@@ -109,7 +143,7 @@ func testVerificationMethodMap(t *testing.T) {
 			Id:          "did:example:123#4d98ef1d2c5947a586b2226b200ade72",
 			Type:        "JsonWebKey2020",
 			Controller:  "did:example:123",
-			PubicKeyJWK: pubKey,
+			PubicKeyJWK: expectedKey,
 		},
 	}
 	doc, _ := diddoc.NewBuilder().VerificationMethod(input).Build()
@@ -119,6 +153,20 @@ func testVerificationMethodMap(t *testing.T) {
 	assert.EqualValues(t, "[]diddoc.VerificationMethod", reflect.TypeOf(actualOutput).String())
 }
 
+func testVerificationMethodPrivateKey(t *testing.T) {
+	var privKey map[string]interface{}
+	json.Unmarshal([]byte(`{"crv":"P-256","d":"uQ7CUbkQLyMVgAKcu1kAjxd1Zn8GzVsYdH0Ut5wVMHs","kty":"EC","x":"nAyQZC6WAvSqnttlft7YOJrqmJx47t3-6l97XQfAGlU","y":"OWcile-qNKOsmXUsUDdYTwn39lvA_Qiml5gFMGaFraQ"}`), &privKey)
+
+	input := map[string]interface{}{
+		"id":           "did:example:123#key-1",
+		"type":         "JsonWebKey2020",
+		"controller":   "did:example:123",
+		"publicKeyJwk": privKey,
+	}
+	_, err := diddoc.NewBuilder().VerificationMethod(input).Build()
+	assert.ErrorContains(t, err, "private_key_not_allowed")
+}
+
 func TestVerificationRelation(t *testing.T) {
 	for scenario, fn := range map[string]func(t *testing.T){
 		"verificationRelation": testVerificationRelation,
@@ -160,7 +208,7 @@ func testServiceStruct(t *testing.T) {
 	input := diddoc.Service{
 		Id:              "did:example:123#linked-domain",
 		Type:            "LinkedDomains",
-		ServiceEndpoint: "https://bar.example.com",
+		ServiceEndpoint: diddoc.URIServiceEndpoint("https://bar.example.com"),
 	}
 	expectedOutput := []diddoc.Service{input}
 	doc, _ := diddoc.NewBuilder().Service(input).Build()
@@ -181,7 +229,7 @@ func testServiceMap(t *testing.T) {
 		{
 			Id:              "did:example:123#linked-domain",
 			Type:            "LinkedDomains",
-			ServiceEndpoint: "https://bar.example.com",
+			ServiceEndpoint: diddoc.URIServiceEndpoint("https://bar.example.com"),
 		},
 	}
 	doc, _ := diddoc.NewBuilder().Service(input).Build()
@@ -213,3 +261,37 @@ func TestCustomProperties(t *testing.T) {
 		})
 	}
 }
+
+func TestBuildErrors(t *testing.T) {
+	inputBytes := []byte(`{"id":"did:example:123","alsoKnownAs":["https://example.com/alice",42],"controller":["did:example:123","https://example.com"],"verificationMethod":[{"id":"did:example:123#key-1","type":"Multikey","controller":"did:example:123","publicKeyMultibase":"z6MkhaXgBZDvotDkL5257faiztiGiC2QtKLGpbnnEGta2doK"},"did:example:123#key-2",{"id":"did:example:123#key-3","type":"JsonWebKey2020","controller":"did:example:123","publicKeyJwk":{"kty":"OKP","crv":"Ed25519","x":"VCpo2LMLhn6iWku8MKvSLg2ZAoC-nlOyPVQaO3FxVeQ","d":"nWGxne_9WmC6hEr0kuwsxERJxWl7MmkZcDusAxyuf2A"}}],"authentication":["#key-1",42],"service":[{"id":"#linked-domain","type":"LinkedDomains","serviceEndpoint":true}]}`)
+
+	doc := diddoc.NewDocument()
+	err := json.Unmarshal(inputBytes, doc)
+	require.Error(t, err)
+
+	var buildErr *diddoc.BuildError
+	require.ErrorAs(t, err, &buildErr)
+	var paths []string
+	for _, propertyErr := range buildErr.Errors {
+		paths = append(paths, propertyErr.Path)
+	}
+	assert.Equal(t, []string{"alsoKnownAs[1]", "controller[1]", "verificationMethod[1]", "verificationMethod[2].publicKeyJwk", "authentication[1]", "service[0].serviceEndpoint"}, paths)
+	assert.ErrorContains(t, err, `invalid property "verificationMethod[2].publicKeyJwk": private_key_not_allowed`)
+
+	// the errors of the properties are matched without multiple error unwrapping, which Go 1.19 lacks
+	assert.True(t, buildErr.Is(buildErr.Errors[3].Err))
+	var propertyErr *diddoc.PropertyError
+	require.True(t, buildErr.As(&propertyErr))
+	assert.Equal(t, "alsoKnownAs[1]", propertyErr.Path)
+	assert.Empty(t, doc.Keys())
+}
+
+func TestBuildEmbeddedContext(t *testing.T) {
+	embedded := map[string]interface{}{"@vocab": "https://example.com/vocab#"}
+	doc, err := diddoc.NewBuilder().Context([]interface{}{diddoc.ContextDIDv1, embedded}).Subject("did:example:123").Build()
+	require.NoError(t, err)
+	assert.Equal(t, []interface{}{diddoc.ContextDIDv1, embedded}, doc.Context())
+
+	_, err = diddoc.NewBuilder().Context([]interface{}{diddoc.ContextDIDv1, 42}).Subject("did:example:123").Build()
+	assert.ErrorContains(t, err, `invalid property "@context[1]": invalid_context`)
+}
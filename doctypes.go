@@ -4,7 +4,35 @@
 package diddoc
 
 import (
+	"encoding/json"
 	"time"
+
+	"github.com/lestrrat-go/jwx/v2/jwk"
+)
+
+const (
+	// ContextDIDv1 is the JSON-LD context of DID Core, which is the first context of a DID document
+	ContextDIDv1 string = "https://www.w3.org/ns/did/v1"
+	// ContextMultikeyV1 is the JSON-LD context of the Multikey verification method type
+	ContextMultikeyV1 string = "https://w3id.org/security/multikey/v1"
+	// ContextJWS2020V1 is the JSON-LD context of the JsonWebKey2020 verification method type
+	ContextJWS2020V1 string = "https://w3id.org/security/suites/jws-2020/v1"
+	// ContextDataIntegrityV2 is the JSON-LD context of Data Integrity proofs
+	ContextDataIntegrityV2 string = "https://w3id.org/security/data-integrity/v2"
+)
+
+const (
+	MultikeyType       string = "Multikey"
+	JsonWebKey2020Type string = "JsonWebKey2020"
+
+	// the legacy verification method types, their raw publicKeyBase58 or publicKeyHex is interpreted by the type
+	Ed25519VerificationKey2018Type        string = "Ed25519VerificationKey2018"
+	Ed25519VerificationKey2020Type        string = "Ed25519VerificationKey2020"
+	X25519KeyAgreementKey2019Type         string = "X25519KeyAgreementKey2019"
+	X25519KeyAgreementKey2020Type         string = "X25519KeyAgreementKey2020"
+	EcdsaSecp256k1VerificationKey2019Type string = "EcdsaSecp256k1VerificationKey2019"
+	EcdsaSecp256r1VerificationKey2019Type string = "EcdsaSecp256r1VerificationKey2019"
+	Bls12381G2Key2020Type                 string = "Bls12381G2Key2020"
 )
 
 type ProofPurpose string
@@ -21,35 +49,137 @@ func (p ProofPurpose) String() string {
 	return string(p)
 }
 
-// DocumentMetadata document metadata, consist of the REQUIRED attributes.
+// DocumentMetadata document metadata, as defined in DID Core 7.1.3 DID Document Metadata.
 type DocumentMetadata struct {
+	// Created is the timestamp of the Create operation.
+	Created time.Time `json:"created,omitempty"`
+	// Updated is the timestamp of the last Update operation.
+	Updated time.Time `json:"updated,omitempty"`
 	// Deactivated is deactivated flag key.
 	Deactivated bool `json:"deactivated"`
+	// NextUpdate is the timestamp of the next Update operation, when the resolved document is not the latest version.
+	NextUpdate time.Time `json:"nextUpdate,omitempty"`
+	// VersionId is the version of the last Update operation.
+	VersionId string `json:"versionId,omitempty"`
+	// NextVersionId is the version of the next Update operation, when the resolved document is not the latest version.
+	NextVersionId string `json:"nextVersionId,omitempty"`
+	// EquivalentId are DIDs that are logically equivalent to the resolved DID.
+	EquivalentId []string `json:"equivalentId,omitempty"`
+	// CanonicalId is the canonical DID of the resolved DID.
+	CanonicalId string `json:"canonicalId,omitempty"`
+}
+
+// documentMetadataJSON is the JSON representation of the document metadata, which omits the zero timestamps
+type documentMetadataJSON struct {
+	Created       *time.Time `json:"created,omitempty"`
+	Updated       *time.Time `json:"updated,omitempty"`
+	Deactivated   bool       `json:"deactivated"`
+	NextUpdate    *time.Time `json:"nextUpdate,omitempty"`
+	VersionId     string     `json:"versionId,omitempty"`
+	NextVersionId string     `json:"nextVersionId,omitempty"`
+	EquivalentId  []string   `json:"equivalentId,omitempty"`
+	CanonicalId   string     `json:"canonicalId,omitempty"`
+}
+
+func (m DocumentMetadata) MarshalJSON() ([]byte, error) {
+	return json.Marshal(documentMetadataJSON{
+		Created:       timeOrNil(m.Created),
+		Updated:       timeOrNil(m.Updated),
+		Deactivated:   m.Deactivated,
+		NextUpdate:    timeOrNil(m.NextUpdate),
+		VersionId:     m.VersionId,
+		NextVersionId: m.NextVersionId,
+		EquivalentId:  m.EquivalentId,
+		CanonicalId:   m.CanonicalId,
+	})
+}
+
+func timeOrNil(t time.Time) *time.Time {
+	if t.IsZero() {
+		return nil
+	}
+	return &t
 }
 
 type Context []string
 
+// Proof is a proof of the document, a Data Integrity proof has a proofValue and a JSON Web Signature proof has a jws.
 type Proof struct {
 	Type               string       `json:"type,omitempty"`
+	Cryptosuite        string       `json:"cryptosuite,omitempty"`
 	Created            time.Time    `json:"created,omitempty"`
 	VerificationMethod string       `json:"verificationMethod,omitempty"`
 	ProofPurpose       ProofPurpose `json:"proofPurpose,omitempty"`
+	Domain             string       `json:"domain,omitempty"`
+	Challenge          string       `json:"challenge,omitempty"`
+	Nonce              string       `json:"nonce,omitempty"`
+	ProofValue         string       `json:"proofValue,omitempty"`
+	JWS                string       `json:"jws,omitempty"`
+	// members are the members of a decoded proof, including those that the proof does not model
+	members map[string]interface{}
+}
+
+// proofJSON is the JSON representation of the proof, which omits the zero created timestamp
+type proofJSON struct {
+	Type               string       `json:"type,omitempty"`
+	Cryptosuite        string       `json:"cryptosuite,omitempty"`
+	Created            *time.Time   `json:"created,omitempty"`
+	VerificationMethod string       `json:"verificationMethod,omitempty"`
+	ProofPurpose       ProofPurpose `json:"proofPurpose,omitempty"`
+	Domain             string       `json:"domain,omitempty"`
+	Challenge          string       `json:"challenge,omitempty"`
 	Nonce              string       `json:"nonce,omitempty"`
-	ProofValue         *string      `json:"jws,omitempty"`
+	ProofValue         string       `json:"proofValue,omitempty"`
+	JWS                string       `json:"jws,omitempty"`
+}
+
+func (p Proof) MarshalJSON() ([]byte, error) {
+	return json.Marshal(proofJSON{
+		Type:               p.Type,
+		Cryptosuite:        p.Cryptosuite,
+		Created:            timeOrNil(p.Created),
+		VerificationMethod: p.VerificationMethod,
+		ProofPurpose:       p.ProofPurpose,
+		Domain:             p.Domain,
+		Challenge:          p.Challenge,
+		Nonce:              p.Nonce,
+		ProofValue:         p.ProofValue,
+		JWS:                p.JWS,
+	})
+}
+
+// UnmarshalJSON decodes the proof, and keeps its members for the proof configuration of the verification
+func (p *Proof) UnmarshalJSON(data []byte) error {
+	type proof Proof
+	var decoded proof
+	if err := json.Unmarshal(data, &decoded); err != nil {
+		return err
+	}
+	var members map[string]interface{}
+	if err := json.Unmarshal(data, &members); err != nil {
+		return err
+	}
+	*p = Proof(decoded)
+	p.members = members
+	return nil
 }
 
 type VerificationMethod struct {
-	Id                 string      `json:"id,omitempty"`
-	Type               string      `json:"type,omitempty"`
-	Controller         string      `json:"controller,omitempty"`
-	PubicKeyJWK        interface{} `json:"publicKeyJwk,omitempty"`
-	PublicKeyMultibase string      `json:"publicKeyMultibase,omitempty"`
+	Id                  string  `json:"id,omitempty"`
+	Type                string  `json:"type,omitempty"`
+	Controller          string  `json:"controller,omitempty"`
+	PubicKeyJWK         jwk.Key `json:"publicKeyJwk,omitempty"`
+	PublicKeyMultibase  string  `json:"publicKeyMultibase,omitempty"`
+	PublicKeyBase58     string  `json:"publicKeyBase58,omitempty"`
+	PublicKeyPem        string  `json:"publicKeyPem,omitempty"`
+	PublicKeyHex        string  `json:"publicKeyHex,omitempty"`
+	BlockchainAccountId string  `json:"blockchainAccountId,omitempty"`
 }
 
 type VerificationRelation interface{}
 
 type Service struct {
-	Id              string `json:"id"`
-	Type            string `json:"type"`
-	ServiceEndpoint string `json:"serviceEndpoint"`
+	Id              string          `json:"id"`
+	Type            string          `json:"type"`
+	ServiceEndpoint ServiceEndpoint `json:"serviceEndpoint"`
 }
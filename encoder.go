@@ -19,6 +19,11 @@ var (
 	errMapNotEqual                error = errors.New("unsupported_map")
 )
 
+// decoder is implemented by destination types that decode themselves from the source value
+type decoder interface {
+	decode(v interface{}) error
+}
+
 func encode(d interface{}, s interface{}) error {
 	dv := reflect.ValueOf(d)
 	if dv.Kind() != reflect.Pointer || dv.IsNil() {
@@ -40,6 +45,11 @@ func encode(d interface{}, s interface{}) error {
 }
 
 func valueEncoder(dv reflect.Value, dt reflect.Type, sv reflect.Value) error {
+	if dv.CanAddr() && sv.CanInterface() {
+		if d, ok := dv.Addr().Interface().(decoder); ok {
+			return d.decode(sv.Interface())
+		}
+	}
 	switch dt.Kind() {
 
 	case reflect.String:
@@ -122,21 +132,26 @@ func arrayEncoder(dv reflect.Value, dt reflect.Type, sv reflect.Value) error {
 	case reflect.Array, reflect.Slice:
 		if dt.Elem().Kind() == reflect.String && sv.Type().Elem().Kind() == reflect.Uint8 {
 			xv := reflect.New(dt.Elem()).Elem()
-			valueEncoder(xv, dt.Elem(), sv)
+			if err := valueEncoder(xv, dt.Elem(), sv); err != nil {
+				return err
+			}
 			dv.Set(reflect.Append(dv, xv))
 		} else {
 			for i := 0; i < sv.Len(); i++ {
 				xv := reflect.New(dt.Elem()).Elem()
-
-				valueEncoder(xv, xv.Type(), sv.Index(i))
+				if err := valueEncoder(xv, xv.Type(), sv.Index(i)); err != nil {
+					return err
+				}
 				dv.Set(reflect.Append(dv, xv))
 			}
 		}
 	case reflect.Interface:
-		valueEncoder(dv, dt, sv.Elem())
+		return valueEncoder(dv, dt, sv.Elem())
 	default:
 		xv := reflect.New(dt.Elem()).Elem()
-		valueEncoder(xv, dt.Elem(), sv)
+		if err := valueEncoder(xv, dt.Elem(), sv); err != nil {
+			return err
+		}
 		dv.Set(reflect.Append(dv, xv))
 	}
 	return nil
@@ -179,7 +194,7 @@ func structEncoder(dv reflect.Value, dt reflect.Type, sv reflect.Value) error {
 
 			if sourceValue.IsValid() && sourceValue.CanInterface() {
 				if err := valueEncoder(xv.Field(i), dt.Field(i).Type, sourceValue); err != nil {
-					return err
+					return propertyError(key[0], err)
 				}
 			}
 		}
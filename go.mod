@@ -2,16 +2,20 @@ module github.com/gossif/diddoc
 
 go 1.19
 
-require github.com/lestrrat-go/jwx/v2 v2.0.8
+require (
+	github.com/lestrrat-go/jwx/v2 v2.0.8
+	github.com/piprate/json-gold v0.5.0
+)
 
 require (
 	github.com/davecgh/go-spew v1.1.1 // indirect
 	github.com/pmezard/go-difflib v1.0.0 // indirect
+	github.com/pquerna/cachecontrol v0.0.0-20180517163645-1555304b9b35 // indirect
 	gopkg.in/yaml.v3 v3.0.1 // indirect
 )
 
 require (
-	github.com/decred/dcrd/dcrec/secp256k1/v4 v4.1.0 // indirect
+	github.com/decred/dcrd/dcrec/secp256k1/v4 v4.1.0
 	github.com/goccy/go-json v0.10.0 // indirect
 	github.com/lestrrat-go/blackmagic v1.0.1 // indirect
 	github.com/lestrrat-go/httpcc v1.0.1 // indirect
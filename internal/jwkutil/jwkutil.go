@@ -0,0 +1,92 @@
+// Copyright 2023 The Go SSI Framework Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package jwkutil converts between JWKs and public keys. The secp256k1 curve is handled
+// here, as jwx only supports it when built with the jwx_es256k tag.
+package jwkutil
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+
+	"github.com/decred/dcrd/dcrec/secp256k1/v4"
+	"github.com/lestrrat-go/jwx/v2/jwa"
+	"github.com/lestrrat-go/jwx/v2/jwk"
+)
+
+// crvSecp256k1 is the curve name of secp256k1, jwa only defines it with the jwx_es256k tag
+const crvSecp256k1 jwa.EllipticCurveAlgorithm = "secp256k1"
+
+var (
+	errInvalidKey error = errors.New("invalid_key")
+)
+
+// FromPublicKey creates a JWK from the public key
+func FromPublicKey(key crypto.PublicKey) (jwk.Key, error) {
+	if k, ok := key.(*ecdsa.PublicKey); ok && k.Curve == secp256k1.S256() {
+		return fromSecp256k1(k)
+	}
+	return jwk.FromRaw(key)
+}
+
+// PublicKey returns the public key of the JWK
+func PublicKey(key jwk.Key) (crypto.PublicKey, error) {
+	if k, ok := key.(jwk.ECDSAPublicKey); ok && k.Crv() == crvSecp256k1 {
+		return toSecp256k1(k.X(), k.Y())
+	}
+	if k, ok := key.(jwk.ECDSAPrivateKey); ok && k.Crv() == crvSecp256k1 {
+		return toSecp256k1(k.X(), k.Y())
+	}
+	pubKey, err := key.PublicKey()
+	if err != nil {
+		return nil, err
+	}
+	var raw interface{}
+	if err := pubKey.Raw(&raw); err != nil {
+		return nil, err
+	}
+	return raw, nil
+}
+
+func fromSecp256k1(key *ecdsa.PublicKey) (jwk.Key, error) {
+	coordinate := func(b []byte) string {
+		padded := make([]byte, 32)
+		copy(padded[32-len(b):], b)
+		return base64.RawURLEncoding.EncodeToString(padded)
+	}
+	data, err := json.Marshal(map[string]string{
+		"kty": jwa.EC.String(),
+		"crv": string(crvSecp256k1),
+		"x":   coordinate(key.X.Bytes()),
+		"y":   coordinate(key.Y.Bytes()),
+	})
+	if err != nil {
+		return nil, err
+	}
+	return jwk.ParseKey(data)
+}
+
+func toSecp256k1(xBytes, yBytes []byte) (crypto.PublicKey, error) {
+	var x, y secp256k1.FieldVal
+	if len(xBytes) > 32 || len(yBytes) > 32 || x.SetByteSlice(xBytes) || y.SetByteSlice(yBytes) {
+		return nil, errInvalidKey
+	}
+	key := secp256k1.NewPublicKey(&x, &y)
+	if !key.IsOnCurve() {
+		return nil, errInvalidKey
+	}
+	return key.ToECDSA(), nil
+}
+
+// IsPrivate reports whether the JWK contains private or secret key material
+func IsPrivate(key jwk.Key) bool {
+	switch key.(type) {
+	case jwk.RSAPrivateKey, jwk.ECDSAPrivateKey, jwk.OKPPrivateKey, jwk.SymmetricKey:
+		return true
+	}
+	return false
+}
@@ -0,0 +1,172 @@
+// Copyright 2023 The Go SSI Framework Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+package diddoc
+
+import (
+	"bytes"
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"encoding/base64"
+	"fmt"
+
+	"github.com/decred/dcrd/dcrec/secp256k1/v4"
+	"github.com/lestrrat-go/jwx/v2/jwa"
+	"github.com/lestrrat-go/jwx/v2/jws"
+	"github.com/lestrrat-go/jwx/v2/jwt"
+)
+
+// VerifyJWS verifies the compact JWS, signed by the verification method of its kid header, and returns the payload.
+// The kid must be a DID URL of the subject of the document, and the verification method must be authorized for the
+// purpose, as reported by IsAuthorized. The alg header must be an algorithm of the key type and curve.
+func (d *Document) VerifyJWS(token []byte, purpose ProofPurpose) ([]byte, error) {
+	message, kid, err := parseCompactJWS(token)
+	if err != nil {
+		return nil, err
+	}
+	return d.verifyJWS(token, message, kid, purpose, lookupOptions{ctx: context.Background()})
+}
+
+// VerifyJWS verifies the compact JWS, signed by the verification method of its kid header, and returns the payload.
+// The kid must be an absolute DID URL, the document of its DID is resolved by the resolver.
+func VerifyJWS(ctx context.Context, resolver Resolver, token []byte, purpose ProofPurpose) ([]byte, error) {
+	message, kid, err := parseCompactJWS(token)
+	if err != nil {
+		return nil, err
+	}
+	controller, err := resolveKid(ctx, resolver, kid)
+	if err != nil {
+		return nil, err
+	}
+	return controller.verifyJWS(token, message, kid, purpose, lookupOptions{ctx: ctx, resolver: resolver})
+}
+
+// VerifyJWT verifies the signature of the JWT like VerifyJWS, and validates its claims, such as the expiration time
+func (d *Document) VerifyJWT(token []byte, purpose ProofPurpose) (jwt.Token, error) {
+	if _, err := d.VerifyJWS(token, purpose); err != nil {
+		return nil, err
+	}
+	// the signature is verified, only the claims are left to validate
+	return jwt.Parse(token, jwt.WithVerify(false), jwt.WithValidate(true))
+}
+
+// VerifyJWT verifies the signature of the JWT like VerifyJWS, and validates its claims, such as the expiration time
+func VerifyJWT(ctx context.Context, resolver Resolver, token []byte, purpose ProofPurpose) (jwt.Token, error) {
+	if _, err := VerifyJWS(ctx, resolver, token, purpose); err != nil {
+		return nil, err
+	}
+	return jwt.Parse(token, jwt.WithVerify(false), jwt.WithValidate(true))
+}
+
+func (d *Document) verifyJWS(token []byte, message *jws.Message, kid string, purpose ProofPurpose, o lookupOptions) ([]byte, error) {
+	subject := d.Subject()
+	kidURL, err := ParseDIDURL(kid)
+	if err != nil {
+		return nil, err
+	}
+	if kidURL.ResolveReference(subject).DID.String() != subject.String() {
+		return nil, fmt.Errorf("%w: %s is not a DID URL of %s", errNotAuthorized, kid, subject)
+	}
+	verificationMethod, err := d.authorize(kid, purpose, o)
+	if err != nil {
+		return nil, err
+	}
+	key, err := verificationMethod.PublicKey()
+	if err != nil {
+		return nil, err
+	}
+	alg := message.Signatures()[0].ProtectedHeaders().Algorithm()
+	algs, err := jwsAlgorithms(key)
+	if err != nil {
+		return nil, err
+	}
+	if !containsAlgorithm(algs, alg) {
+		return nil, fmt.Errorf("%w: %s is not an algorithm of the key of %s", errAlgMismatch, alg, kid)
+	}
+	if alg == jwa.ES256K {
+		// jwx only verifies ES256K when built with the jwx_es256k tag
+		return verifyES256K(token, message, key)
+	}
+	payload, err := jws.Verify(token, jws.WithKey(alg, key))
+	if err != nil {
+		return nil, errInvalidSignature
+	}
+	return payload, nil
+}
+
+// parseCompactJWS parses the compact JWS, which must have a single signature and a kid in its protected header
+func parseCompactJWS(token []byte) (*jws.Message, string, error) {
+	if bytes.Count(token, []byte(".")) != 2 {
+		return nil, "", errInvalidJWS
+	}
+	message, err := jws.Parse(token)
+	if err != nil || len(message.Signatures()) != 1 {
+		return nil, "", errInvalidJWS
+	}
+	kid := message.Signatures()[0].ProtectedHeaders().KeyID()
+	if kid == "" {
+		return nil, "", errMissingMethod
+	}
+	return message, kid, nil
+}
+
+// resolveKid resolves the document of the DID of the kid, which must be an absolute DID URL
+func resolveKid(ctx context.Context, resolver Resolver, kid string) (*Document, error) {
+	kidURL, err := ParseDIDURL(kid)
+	if err != nil {
+		return nil, err
+	}
+	if kidURL.IsRelative() {
+		return nil, fmt.Errorf("%w: %s", errInvalidDIDURL, kid)
+	}
+	return resolveController(ctx, resolver, kidURL.DID.String())
+}
+
+// verifyES256K verifies the ES256K signature of the compact JWS over its encoded header and payload
+func verifyES256K(token []byte, message *jws.Message, key crypto.PublicKey) ([]byte, error) {
+	parts := bytes.Split(token, []byte("."))
+	signature, err := base64.RawURLEncoding.DecodeString(string(parts[2]))
+	if err != nil {
+		return nil, errInvalidJWS
+	}
+	signingInput := token[:len(parts[0])+1+len(parts[1])]
+	if err := (cryptosuite{}).verify(key, crypto.SHA256, signingInput, signature); err != nil {
+		return nil, err
+	}
+	return message.Payload(), nil
+}
+
+// jwsAlgorithms returns the JWS algorithms of the key type and curve of the public key
+func jwsAlgorithms(key crypto.PublicKey) ([]jwa.SignatureAlgorithm, error) {
+	switch k := key.(type) {
+	case ed25519.PublicKey:
+		return []jwa.SignatureAlgorithm{jwa.EdDSA}, nil
+	case *ecdsa.PublicKey:
+		switch k.Curve {
+		case elliptic.P256():
+			return []jwa.SignatureAlgorithm{jwa.ES256}, nil
+		case elliptic.P384():
+			return []jwa.SignatureAlgorithm{jwa.ES384}, nil
+		case elliptic.P521():
+			return []jwa.SignatureAlgorithm{jwa.ES512}, nil
+		case secp256k1.S256():
+			return []jwa.SignatureAlgorithm{jwa.ES256K}, nil
+		}
+	case *rsa.PublicKey:
+		return []jwa.SignatureAlgorithm{jwa.RS256, jwa.RS384, jwa.RS512, jwa.PS256, jwa.PS384, jwa.PS512}, nil
+	}
+	return nil, errKeyTypeMismatch
+}
+
+func containsAlgorithm(algs []jwa.SignatureAlgorithm, alg jwa.SignatureAlgorithm) bool {
+	for _, a := range algs {
+		if a == alg {
+			return true
+		}
+	}
+	return false
+}
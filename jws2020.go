@@ -0,0 +1,169 @@
+// Copyright 2023 The Go SSI Framework Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+package diddoc
+
+import (
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"strings"
+
+	"github.com/lestrrat-go/jwx/v2/jwa"
+	"github.com/lestrrat-go/jwx/v2/jws"
+	"github.com/piprate/json-gold/ld"
+)
+
+const (
+	// JsonWebSignature2020Type is the proof type of detached JWS proofs with an unencoded payload
+	JsonWebSignature2020Type string = "JsonWebSignature2020"
+
+	b64Header string = "b64"
+)
+
+var (
+	errInvalidJWS  error = errors.New("invalid_jws")
+	errAlgMismatch error = errors.New("alg_mismatch")
+)
+
+// SignJWS adds a JsonWebSignature2020 proof to the document, signed by the signer. The JsonWebSignature2020
+// context is added to the document when it is absent.
+func (d *Document) SignJWS(signer crypto.Signer, opts ProofOptions) error {
+	if err := d.addContext(ContextJWS2020V1); err != nil {
+		return err
+	}
+	document, err := d.unsecuredJSON()
+	if err != nil {
+		return err
+	}
+	proof, err := CreateJWSProof(document, signer, opts)
+	if err != nil {
+		return err
+	}
+	return d.addProof(proof)
+}
+
+// CreateJWSProof creates a JsonWebSignature2020 proof of the JSON-LD payload, which must not contain the proof.
+// The jws of the proof is a detached JWS with an unencoded payload (RFC 7797).
+func CreateJWSProof(payload []byte, signer crypto.Signer, opts ProofOptions) (Proof, error) {
+	if opts.VerificationMethod == "" {
+		return Proof{}, errMissingMethod
+	}
+	alg, err := jwsAlgorithm(signer.Public())
+	if err != nil {
+		return Proof{}, err
+	}
+	proof := newProof(JsonWebSignature2020Type, opts)
+	verifyData, err := jwsVerifyData(payload, proof, documentLoader(opts.DocumentLoader))
+	if err != nil {
+		return Proof{}, err
+	}
+	headers := jws.NewHeaders()
+	if err := headers.Set(b64Header, false); err != nil {
+		return Proof{}, err
+	}
+	if err := headers.Set(jws.CriticalKey, []string{b64Header}); err != nil {
+		return Proof{}, err
+	}
+	signed, err := jws.Sign(nil, jws.WithKey(alg, signer, jws.WithProtectedHeaders(headers)), jws.WithDetachedPayload(verifyData))
+	if err != nil {
+		return Proof{}, err
+	}
+	proof.JWS = string(signed)
+	return proof, nil
+}
+
+// VerifyJWSProof verifies the JsonWebSignature2020 proof of the JSON-LD payload, which must not contain the proof.
+// The verification method must be authorized by the controller document as in VerifyProof, the DID documents of
+// other DIDs are resolved by the resolver.
+func VerifyJWSProof(payload []byte, proof Proof, controller *Document, resolver Resolver, opts ...VerifyOption) error {
+	options := verifyOptions{loader: defaultDocumentLoader}
+	for _, opt := range opts {
+		opt(&options)
+	}
+	if proof.Type != JsonWebSignature2020Type {
+		return errUnsupportedProofType
+	}
+	if err := options.check(proof); err != nil {
+		return err
+	}
+	verificationMethod, err := controller.authorize(proof.VerificationMethod, proof.ProofPurpose, lookupOptions{ctx: context.Background(), resolver: resolver})
+	if err != nil {
+		return err
+	}
+	key, err := verificationMethod.PublicKey()
+	if err != nil {
+		return err
+	}
+	return verifyJWS2020(payload, proof, key, options.loader)
+}
+
+func verifyJWS2020(payload []byte, proof Proof, key crypto.PublicKey, loader ld.DocumentLoader) error {
+	alg, err := jwsAlgorithm(key)
+	if err != nil {
+		return err
+	}
+	// the algorithm of the header must be the algorithm of the key, and the payload must be unencoded
+	encodedHeader, _, found := strings.Cut(proof.JWS, ".")
+	if !found {
+		return errInvalidJWS
+	}
+	headerBytes, err := base64.RawURLEncoding.DecodeString(encodedHeader)
+	if err != nil {
+		return errInvalidJWS
+	}
+	var header struct {
+		Alg string `json:"alg"`
+		B64 *bool  `json:"b64"`
+	}
+	if err := json.Unmarshal(headerBytes, &header); err != nil {
+		return errInvalidJWS
+	}
+	if header.Alg != alg.String() {
+		return errAlgMismatch
+	}
+	if header.B64 == nil || *header.B64 {
+		return errInvalidJWS
+	}
+	verifyData, err := jwsVerifyData(payload, proof, loader)
+	if err != nil {
+		return err
+	}
+	if _, err := jws.Verify([]byte(proof.JWS), jws.WithKey(alg, key), jws.WithDetachedPayload(verifyData)); err != nil {
+		return errInvalidSignature
+	}
+	return nil
+}
+
+// jwsVerifyData is the hash of the canonical proof configuration followed by the hash of the canonical payload
+func jwsVerifyData(payload []byte, proof Proof, loader ld.DocumentLoader) ([]byte, error) {
+	config, err := proofConfig(proof, payload)
+	if err != nil {
+		return nil, err
+	}
+	return cryptosuite{rdfc: true}.hashData(payload, config, crypto.SHA256, loader)
+}
+
+// jwsAlgorithm returns the JWS algorithm of the public key, as registered by JsonWebSignature2020
+func jwsAlgorithm(key crypto.PublicKey) (jwa.SignatureAlgorithm, error) {
+	switch k := key.(type) {
+	case ed25519.PublicKey:
+		return jwa.EdDSA, nil
+	case *ecdsa.PublicKey:
+		switch k.Curve {
+		case elliptic.P256():
+			return jwa.ES256, nil
+		case elliptic.P384():
+			return jwa.ES384, nil
+		}
+	case *rsa.PublicKey:
+		return jwa.PS256, nil
+	}
+	return "", errKeyTypeMismatch
+}
@@ -0,0 +1,118 @@
+// Copyright 2023 The Go SSI Framework Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+package diddoc_test
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/gossif/diddoc"
+	"github.com/gossif/diddoc/didkey"
+	"github.com/lestrrat-go/jwx/v2/jwk"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSignJWS(t *testing.T) {
+	_, edKey, _ := ed25519.GenerateKey(rand.Reader)
+	p256Key, _ := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	rsaKey, _ := rsa.GenerateKey(rand.Reader, 2048)
+
+	type errorTestCases struct {
+		description string
+		signer      crypto.Signer
+		expectedAlg string
+	}
+	for _, scenario := range []errorTestCases{
+		{description: "ed25519", signer: edKey, expectedAlg: "EdDSA"},
+		{description: "p-256", signer: p256Key, expectedAlg: "ES256"},
+		{description: "rsa", signer: rsaKey, expectedAlg: "PS256"},
+	} {
+		t.Run(scenario.description, func(t *testing.T) {
+			publicKey, err := jwk.FromRaw(scenario.signer.Public())
+			require.NoError(t, err)
+			doc := signingDocument(t, diddoc.VerificationMethod{Id: "did:example:123#key-1", Type: diddoc.JsonWebKey2020Type, Controller: "did:example:123", PubicKeyJWK: publicKey})
+			require.NoError(t, doc.SignJWS(scenario.signer, diddoc.ProofOptions{VerificationMethod: "did:example:123#key-1", DocumentLoader: testDocumentLoader{}}))
+
+			received := roundTrip(t, doc)
+			assert.NoError(t, received.VerifyProof(nil, diddoc.WithDocumentLoader(testDocumentLoader{})))
+			assert.Contains(t, received.Context(), diddoc.ContextJWS2020V1)
+
+			proofs, err := received.Proofs()
+			require.NoError(t, err)
+			require.Len(t, proofs, 1)
+			assert.Equal(t, diddoc.JsonWebSignature2020Type, proofs[0].Type)
+			assert.Empty(t, proofs[0].ProofValue)
+
+			// detached JWS with an unencoded payload
+			parts := strings.Split(proofs[0].JWS, ".")
+			require.Len(t, parts, 3)
+			assert.Empty(t, parts[1])
+			header, err := base64.RawURLEncoding.DecodeString(parts[0])
+			require.NoError(t, err)
+			assert.JSONEq(t, `{"alg":"`+scenario.expectedAlg+`","b64":false,"crit":["b64"]}`, string(header))
+		})
+	}
+}
+
+func TestVerifyJWSProof(t *testing.T) {
+	_, privateKey, _ := ed25519.GenerateKey(rand.Reader)
+	publicKey, _ := jwk.FromRaw(privateKey.Public())
+	controller := signingDocument(t, diddoc.VerificationMethod{Id: "did:example:123#key-1", Type: diddoc.JsonWebKey2020Type, Controller: "did:example:123", PubicKeyJWK: publicKey})
+
+	payload := []byte(`{"@context":["https://www.w3.org/ns/did/v1"],"id":"urn:uuid:1234","issuer":"did:example:123"}`)
+	proof, err := diddoc.CreateJWSProof(payload, privateKey, diddoc.ProofOptions{VerificationMethod: "did:example:123#key-1", DocumentLoader: testDocumentLoader{}})
+	require.NoError(t, err)
+
+	t.Run("valid", func(t *testing.T) {
+		assert.NoError(t, diddoc.VerifyJWSProof(payload, proof, controller, nil, diddoc.WithDocumentLoader(testDocumentLoader{})))
+	})
+	t.Run("tampered payload", func(t *testing.T) {
+		tampered := []byte(`{"@context":["https://www.w3.org/ns/did/v1"],"id":"urn:uuid:1234","issuer":"did:example:456"}`)
+		assert.ErrorContains(t, diddoc.VerifyJWSProof(tampered, proof, controller, nil, diddoc.WithDocumentLoader(testDocumentLoader{})), "invalid_signature")
+	})
+	t.Run("purpose not authorized", func(t *testing.T) {
+		other := proof
+		other.ProofPurpose = diddoc.KeyAgreement
+		assert.ErrorContains(t, diddoc.VerifyJWSProof(payload, other, controller, nil, diddoc.WithDocumentLoader(testDocumentLoader{})), "not_authorized")
+	})
+	t.Run("encoded payload", func(t *testing.T) {
+		other := proof
+		header := base64.RawURLEncoding.EncodeToString([]byte(`{"alg":"EdDSA"}`))
+		other.JWS = header + proof.JWS[strings.Index(proof.JWS, "."):]
+		assert.ErrorContains(t, diddoc.VerifyJWSProof(payload, other, controller, nil, diddoc.WithDocumentLoader(testDocumentLoader{})), "invalid_jws")
+	})
+	t.Run("alg mismatch", func(t *testing.T) {
+		other := proof
+		header := base64.RawURLEncoding.EncodeToString([]byte(`{"alg":"ES256","b64":false,"crit":["b64"]}`))
+		other.JWS = header + proof.JWS[strings.Index(proof.JWS, "."):]
+		assert.ErrorContains(t, diddoc.VerifyJWSProof(payload, other, controller, nil, diddoc.WithDocumentLoader(testDocumentLoader{})), "alg_mismatch")
+	})
+	t.Run("key of a controller", func(t *testing.T) {
+		controllerPublicKey, controllerPrivateKey, _ := ed25519.GenerateKey(rand.Reader)
+		controllerDID, err := didkey.New(controllerPublicKey)
+		require.NoError(t, err)
+		doc, err := diddoc.NewBuilder().Context(diddoc.ContextDIDv1).Subject("did:example:456").Controller(controllerDID.String()).Build()
+		require.NoError(t, err)
+		proof, err := diddoc.CreateJWSProof(payload, controllerPrivateKey, diddoc.ProofOptions{VerificationMethod: controllerDID.String() + "#" + controllerDID.ID, DocumentLoader: testDocumentLoader{}})
+		require.NoError(t, err)
+		assert.NoError(t, diddoc.VerifyJWSProof(payload, proof, doc, didkey.NewResolver(), diddoc.WithDocumentLoader(testDocumentLoader{})))
+		assert.ErrorContains(t, diddoc.VerifyJWSProof(payload, proof, doc, nil, diddoc.WithDocumentLoader(testDocumentLoader{})), "controller_not_resolved")
+	})
+	t.Run("proof round trip", func(t *testing.T) {
+		data, err := json.Marshal(proof)
+		require.NoError(t, err)
+		var decoded diddoc.Proof
+		require.NoError(t, json.Unmarshal(data, &decoded))
+		assert.NoError(t, diddoc.VerifyJWSProof(payload, decoded, controller, nil, diddoc.WithDocumentLoader(testDocumentLoader{})))
+	})
+}
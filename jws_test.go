@@ -0,0 +1,156 @@
+// Copyright 2023 The Go SSI Framework Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+package diddoc_test
+
+import (
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"testing"
+	"time"
+
+	"github.com/decred/dcrd/dcrec/secp256k1/v4"
+	"github.com/gossif/diddoc"
+	"github.com/gossif/diddoc/didkey"
+	"github.com/lestrrat-go/jwx/v2/jwa"
+	"github.com/lestrrat-go/jwx/v2/jws"
+	"github.com/lestrrat-go/jwx/v2/jwt"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestVerifyJWS(t *testing.T) {
+	_, edKey, _ := ed25519.GenerateKey(rand.Reader)
+	_, otherKey, _ := ed25519.GenerateKey(rand.Reader)
+	p256Key, _ := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+
+	did, err := didkey.New(edKey.Public())
+	require.NoError(t, err)
+	doc, err := didkey.Expand(did.String())
+	require.NoError(t, err)
+	kid := did.String() + "#" + did.ID
+	payload := []byte(`{"hello":"world"}`)
+
+	type errorTestCases struct {
+		description   string
+		token         []byte
+		purpose       diddoc.ProofPurpose
+		expectedError string
+	}
+	for _, scenario := range []errorTestCases{
+		{description: "valid", token: signJWS(t, jwa.EdDSA, edKey, kid, payload), purpose: diddoc.Authentication},
+		{description: "relative kid", token: signJWS(t, jwa.EdDSA, edKey, "#"+did.ID, payload), purpose: diddoc.AssertionMethod},
+		{description: "purpose not authorized", token: signJWS(t, jwa.EdDSA, edKey, kid, payload), purpose: diddoc.KeyAgreement, expectedError: "not_authorized"},
+		{description: "kid of other DID", token: signJWS(t, jwa.EdDSA, edKey, "did:example:123#key-1", payload), purpose: diddoc.Authentication, expectedError: "not_authorized"},
+		{description: "missing kid", token: signJWS(t, jwa.EdDSA, edKey, "", payload), purpose: diddoc.Authentication, expectedError: "missing_verification_method"},
+		{description: "alg mismatch", token: signJWS(t, jwa.ES256, p256Key, kid, payload), purpose: diddoc.Authentication, expectedError: "alg_mismatch"},
+		{description: "invalid signature", token: signJWS(t, jwa.EdDSA, otherKey, kid, payload), purpose: diddoc.Authentication, expectedError: "invalid_signature"},
+		{description: "not compact", token: []byte(`{"payload":"e30","signatures":[]}`), purpose: diddoc.Authentication, expectedError: "invalid_jws"},
+	} {
+		t.Run(scenario.description, func(t *testing.T) {
+			verified, err := doc.VerifyJWS(scenario.token, scenario.purpose)
+			if scenario.expectedError != "" {
+				assert.ErrorContains(t, err, scenario.expectedError)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, payload, verified)
+		})
+	}
+	t.Run("with resolver", func(t *testing.T) {
+		verified, err := diddoc.VerifyJWS(context.Background(), didkey.NewResolver(), signJWS(t, jwa.EdDSA, edKey, kid, payload), diddoc.Authentication)
+		require.NoError(t, err)
+		assert.Equal(t, payload, verified)
+
+		_, err = diddoc.VerifyJWS(context.Background(), didkey.NewResolver(), signJWS(t, jwa.EdDSA, edKey, "#"+did.ID, payload), diddoc.Authentication)
+		assert.ErrorContains(t, err, "invalid_did_url")
+	})
+	t.Run("key of a controller", func(t *testing.T) {
+		// the kid must be a DID URL of the subject, the key of a controller is not accepted
+		controlled, err := diddoc.NewBuilder().Context(diddoc.ContextDIDv1).Subject("did:example:456").Controller(did.String()).Build()
+		require.NoError(t, err)
+
+		_, err = controlled.VerifyJWS(signJWS(t, jwa.EdDSA, edKey, kid, payload), diddoc.Authentication)
+		assert.ErrorContains(t, err, "not_authorized")
+	})
+}
+
+func TestVerifyJWSES256K(t *testing.T) {
+	privateKey, err := secp256k1.GeneratePrivateKey()
+	require.NoError(t, err)
+	signer := privateKey.ToECDSA()
+
+	did, err := didkey.New(&signer.PublicKey)
+	require.NoError(t, err)
+	doc, err := didkey.Expand(did.String())
+	require.NoError(t, err)
+
+	header := base64.RawURLEncoding.EncodeToString([]byte(`{"alg":"ES256K","kid":"` + did.String() + "#" + did.ID + `"}`))
+	signingInput := header + "." + base64.RawURLEncoding.EncodeToString([]byte(`{"hello":"world"}`))
+	sum := sha256.Sum256([]byte(signingInput))
+	r, s, err := ecdsa.Sign(rand.Reader, signer, sum[:])
+	require.NoError(t, err)
+	signature := make([]byte, 64)
+	r.FillBytes(signature[:32])
+	s.FillBytes(signature[32:])
+	token := signingInput + "." + base64.RawURLEncoding.EncodeToString(signature)
+
+	verified, err := doc.VerifyJWS([]byte(token), diddoc.AssertionMethod)
+	require.NoError(t, err)
+	assert.Equal(t, `{"hello":"world"}`, string(verified))
+
+	signature[0] ^= 0xff
+	tampered := signingInput + "." + base64.RawURLEncoding.EncodeToString(signature)
+	_, err = doc.VerifyJWS([]byte(tampered), diddoc.AssertionMethod)
+	assert.ErrorContains(t, err, "invalid_signature")
+}
+
+func TestVerifyJWT(t *testing.T) {
+	_, edKey, _ := ed25519.GenerateKey(rand.Reader)
+	did, err := didkey.New(edKey.Public())
+	require.NoError(t, err)
+	doc, err := didkey.Expand(did.String())
+	require.NoError(t, err)
+	kid := did.String() + "#" + did.ID
+
+	signJWT := func(expiration time.Time) []byte {
+		token, err := jwt.NewBuilder().Issuer(did.String()).Expiration(expiration).Build()
+		require.NoError(t, err)
+		headers := jws.NewHeaders()
+		require.NoError(t, headers.Set(jws.KeyIDKey, kid))
+		signed, err := jwt.Sign(token, jwt.WithKey(jwa.EdDSA, edKey, jws.WithProtectedHeaders(headers)))
+		require.NoError(t, err)
+		return signed
+	}
+	t.Run("valid", func(t *testing.T) {
+		token, err := doc.VerifyJWT(signJWT(time.Now().Add(time.Hour)), diddoc.Authentication)
+		require.NoError(t, err)
+		assert.Equal(t, did.String(), token.Issuer())
+	})
+	t.Run("expired", func(t *testing.T) {
+		_, err := doc.VerifyJWT(signJWT(time.Now().Add(-time.Hour)), diddoc.Authentication)
+		assert.Error(t, err)
+	})
+	t.Run("with resolver", func(t *testing.T) {
+		token, err := diddoc.VerifyJWT(context.Background(), didkey.NewResolver(), signJWT(time.Now().Add(time.Hour)), diddoc.AssertionMethod)
+		require.NoError(t, err)
+		assert.Equal(t, did.String(), token.Issuer())
+	})
+}
+
+// signJWS signs the payload into a compact JWS, with the kid in the protected header
+func signJWS(t *testing.T, alg jwa.SignatureAlgorithm, key crypto.Signer, kid string, payload []byte) []byte {
+	headers := jws.NewHeaders()
+	if kid != "" {
+		require.NoError(t, headers.Set(jws.KeyIDKey, kid))
+	}
+	signed, err := jws.Sign(payload, jws.WithKey(alg, key, jws.WithProtectedHeaders(headers)))
+	require.NoError(t, err)
+	return signed
+}
@@ -0,0 +1,116 @@
+// Copyright 2023 The Go SSI Framework Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+package diddoc
+
+import (
+	"crypto/ed25519"
+	"errors"
+	"fmt"
+
+	"github.com/gossif/diddoc/internal/jwkutil"
+	"github.com/gossif/diddoc/multikey"
+)
+
+var (
+	errNotEd25519Key error = errors.New("not_an_ed25519_key")
+)
+
+// X25519KeyAgreement derives the X25519 key agreement method from an Ed25519 verification method, following the
+// did:key derivation rules. The fragment of the id is the multibase fingerprint of the X25519 key, and the type
+// and key format correspond with those of the Ed25519 verification method.
+func (v VerificationMethod) X25519KeyAgreement() (VerificationMethod, error) {
+	pubKey, err := v.PublicKey()
+	if err != nil {
+		return VerificationMethod{}, err
+	}
+	edKey, ok := pubKey.(ed25519.PublicKey)
+	if !ok {
+		return VerificationMethod{}, errNotEd25519Key
+	}
+	xKey, err := multikey.Ed25519ToX25519(edKey)
+	if err != nil {
+		return VerificationMethod{}, err
+	}
+	fingerprint, err := multikey.EncodePublicKey(xKey)
+	if err != nil {
+		return VerificationMethod{}, err
+	}
+	did, err := v.did()
+	if err != nil {
+		return VerificationMethod{}, err
+	}
+	keyAgreementMethod := VerificationMethod{
+		Id:         did.String() + "#" + fingerprint,
+		Controller: v.Controller,
+	}
+	switch v.Type {
+	case MultikeyType:
+		keyAgreementMethod.Type = MultikeyType
+		keyAgreementMethod.PublicKeyMultibase = fingerprint
+	case Ed25519VerificationKey2020Type:
+		keyAgreementMethod.Type = X25519KeyAgreementKey2020Type
+		keyAgreementMethod.PublicKeyMultibase = fingerprint
+	case Ed25519VerificationKey2018Type:
+		keyAgreementMethod.Type = X25519KeyAgreementKey2019Type
+		keyAgreementMethod.PublicKeyBase58 = multikey.EncodeBase58(xKey)
+	case JsonWebKey2020Type:
+		key, err := jwkutil.FromPublicKey(xKey)
+		if err != nil {
+			return VerificationMethod{}, err
+		}
+		keyAgreementMethod.Type = JsonWebKey2020Type
+		keyAgreementMethod.PubicKeyJWK = key
+	default:
+		return VerificationMethod{}, fmt.Errorf("%w: %q", errUnsupportedVerificationMethodType, v.Type)
+	}
+	return keyAgreementMethod, nil
+}
+
+// did returns the DID of the verification method id, the controller is used when the id is relative
+func (v VerificationMethod) did() (DID, error) {
+	u, err := ParseDIDURL(v.Id)
+	if err == nil && !u.IsRelative() {
+		return u.DID, nil
+	}
+	return ParseDID(v.Controller)
+}
+
+// AddX25519KeyAgreement derives the X25519 key agreement method from the Ed25519 verification method with the id,
+// and adds it to the verification methods and the keyAgreement relationship of the document. Adding the same key
+// agreement method twice has no effect.
+func (d *Document) AddX25519KeyAgreement(keyId string) (VerificationMethod, error) {
+	defer d.lock()()
+	if d.readOnly {
+		return VerificationMethod{}, errReadOnly
+	}
+	verificationMethod, err := d.getVerificationMethodById(keyId)
+	if err != nil {
+		return VerificationMethod{}, err
+	}
+	subject := d.subject()
+	verificationMethod.Id = normalizeDIDURL(verificationMethod.Id, subject)
+
+	keyAgreementMethod, err := verificationMethod.X25519KeyAgreement()
+	if err != nil {
+		return VerificationMethod{}, err
+	}
+	if _, err := d.getVerificationMethodById(keyAgreementMethod.Id); err != nil {
+		var verificationMethods []VerificationMethod
+		if err := encode(&verificationMethods, d.get(verificationMethodKey)); err != nil {
+			return VerificationMethod{}, err
+		}
+		d.set(verificationMethodKey, append(verificationMethods, keyAgreementMethod))
+	}
+	var relations []VerificationRelation
+	if err := encode(&relations, d.get(keyAgreementKey)); err != nil {
+		return VerificationMethod{}, err
+	}
+	for _, relation := range relations {
+		if reference, ok := relation.(string); ok && normalizeDIDURL(reference, subject) == keyAgreementMethod.Id {
+			return keyAgreementMethod, nil
+		}
+	}
+	d.set(keyAgreementKey, append(relations, keyAgreementMethod.Id))
+	return keyAgreementMethod, nil
+}
@@ -0,0 +1,64 @@
+// Copyright 2023 The Go SSI Framework Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+package diddoc_test
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/gossif/diddoc"
+	"github.com/gossif/diddoc/didkey"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestX25519KeyAgreement(t *testing.T) {
+	type errorTestCases struct {
+		description        string
+		verificationMethod diddoc.VerificationMethod
+		expectedType       string
+		expectedError      string
+	}
+	for _, scenario := range []errorTestCases{
+		{description: "multikey", verificationMethod: diddoc.VerificationMethod{Id: "did:example:123#key-1", Type: diddoc.MultikeyType, Controller: "did:example:123", PublicKeyMultibase: "z6MkhaXgBZDvotDkL5257faiztiGiC2QtKLGpbnnEGta2doK"}, expectedType: diddoc.MultikeyType},
+		{description: "ed25519 2020", verificationMethod: diddoc.VerificationMethod{Id: "did:example:123#key-1", Type: diddoc.Ed25519VerificationKey2020Type, Controller: "did:example:123", PublicKeyMultibase: "z6MkhaXgBZDvotDkL5257faiztiGiC2QtKLGpbnnEGta2doK"}, expectedType: diddoc.X25519KeyAgreementKey2020Type},
+		{description: "ed25519 2018", verificationMethod: diddoc.VerificationMethod{Id: "did:example:123#key-1", Type: diddoc.Ed25519VerificationKey2018Type, Controller: "did:example:123", PublicKeyBase58: "48GdbJyVULjHDaBNS6ct9oAGtckZUS5v8asrPzvZ7R1w"}, expectedType: diddoc.X25519KeyAgreementKey2019Type},
+		{description: "not ed25519", verificationMethod: diddoc.VerificationMethod{Id: "did:example:123#key-1", Type: diddoc.MultikeyType, Controller: "did:example:123", PublicKeyMultibase: "zDnaerDaTF5BXEavCrfRZEk316dpbLsfPDZ3WJ5hRTPFU2169"}, expectedError: "not_an_ed25519_key"},
+	} {
+		t.Run(scenario.description, func(t *testing.T) {
+			keyAgreementMethod, err := scenario.verificationMethod.X25519KeyAgreement()
+			if scenario.expectedError != "" {
+				assert.ErrorContains(t, err, scenario.expectedError)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, scenario.expectedType, keyAgreementMethod.Type)
+			assert.Equal(t, "did:example:123#z6LSj72tK8brWgZja8NLRwPigth2T9QRiG1uH9oKZuKjdh9p", keyAgreementMethod.Id)
+		})
+	}
+}
+
+func TestAddX25519KeyAgreement(t *testing.T) {
+	inputBytes := []byte(`{"@context":["https://www.w3.org/ns/did/v1","https://w3id.org/security/multikey/v1"],"id":"did:example:123","verificationMethod":[{"id":"#key-1","type":"Multikey","controller":"did:example:123","publicKeyMultibase":"z6MkhaXgBZDvotDkL5257faiztiGiC2QtKLGpbnnEGta2doK"}],"authentication":["#key-1"]}`)
+
+	doc := diddoc.NewDocument()
+	require.NoError(t, json.Unmarshal(inputBytes, doc))
+
+	keyAgreementMethod, err := doc.AddX25519KeyAgreement("#key-1")
+	require.NoError(t, err)
+	_, err = doc.AddX25519KeyAgreement("#key-1")
+	require.NoError(t, err)
+
+	keyAgreement, err := doc.GetAssociatedVerificationMethod(diddoc.KeyAgreement)
+	require.NoError(t, err)
+	require.Len(t, keyAgreement, 1)
+	assert.Equal(t, keyAgreementMethod, keyAgreement[0])
+
+	// the derivation matches the keyAgreement of the did:key
+	didKeyDoc, err := didkey.Expand("did:key:z6MkhaXgBZDvotDkL5257faiztiGiC2QtKLGpbnnEGta2doK")
+	require.NoError(t, err)
+	expected, err := didKeyDoc.GetAssociatedVerificationMethod(diddoc.KeyAgreement)
+	require.NoError(t, err)
+	assert.Equal(t, expected[0].PublicKeyMultibase, keyAgreement[0].PublicKeyMultibase)
+}
@@ -0,0 +1,59 @@
+// Copyright 2023 The Go SSI Framework Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+package diddoc
+
+import (
+	"embed"
+	"encoding/json"
+	"fmt"
+
+	"github.com/piprate/json-gold/ld"
+)
+
+//go:embed contexts/*.jsonld
+var contextFiles embed.FS
+
+// staticContexts maps the URLs of the bundled JSON-LD contexts to their files
+var staticContexts = map[string]string{
+	ContextDIDv1:           "contexts/did-v1.jsonld",
+	ContextDataIntegrityV2: "contexts/data-integrity-v2.jsonld",
+	ContextMultikeyV1:      "contexts/multikey-v1.jsonld",
+	ContextJWS2020V1:       "contexts/jws-2020-v1.jsonld",
+}
+
+type staticDocumentLoader struct {
+	fallback ld.DocumentLoader
+}
+
+// StaticDocumentLoader serves the DID v1, Data Integrity v2, Multikey v1 and JsonWebSignature2020 contexts without
+// network access. Other contexts are loaded by the fallback, when it is nil they are not loaded.
+func StaticDocumentLoader(fallback ld.DocumentLoader) ld.DocumentLoader {
+	return staticDocumentLoader{fallback: fallback}
+}
+
+// RemoteDocumentLoader serves the bundled contexts like StaticDocumentLoader, and fetches the other contexts over
+// the network and caches them
+func RemoteDocumentLoader() ld.DocumentLoader {
+	return StaticDocumentLoader(ld.NewCachingDocumentLoader(ld.NewDefaultDocumentLoader(nil)))
+}
+
+func (l staticDocumentLoader) LoadDocument(u string) (*ld.RemoteDocument, error) {
+	name, ok := staticContexts[u]
+	if !ok {
+		if l.fallback == nil {
+			return nil, ld.NewJsonLdError(ld.LoadingDocumentFailed, fmt.Sprintf("context %s is not bundled", u))
+		}
+		return l.fallback.LoadDocument(u)
+	}
+	data, err := contextFiles.ReadFile(name)
+	if err != nil {
+		return nil, ld.NewJsonLdError(ld.LoadingDocumentFailed, err)
+	}
+	// the document is decoded on every load, as the JSON-LD processor may modify it
+	var document interface{}
+	if err := json.Unmarshal(data, &document); err != nil {
+		return nil, ld.NewJsonLdError(ld.LoadingDocumentFailed, err)
+	}
+	return &ld.RemoteDocument{DocumentURL: u, Document: document}, nil
+}
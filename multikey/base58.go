@@ -0,0 +1,102 @@
+// Copyright 2023 The Go SSI Framework Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+package multikey
+
+import (
+	"errors"
+)
+
+const base58Alphabet string = "123456789ABCDEFGHJKLMNPQRSTUVWXYZabcdefghijkmnopqrstuvwxyz"
+
+var (
+	errInvalidBase58 error = errors.New("invalid_base58")
+
+	base58Index = func() [256]int {
+		var index [256]int
+		for i := range index {
+			index[i] = -1
+		}
+		for i := 0; i < len(base58Alphabet); i++ {
+			index[base58Alphabet[i]] = i
+		}
+		return index
+	}()
+)
+
+// encodeBase58 encodes the bytes with the bitcoin base58 alphabet
+func encodeBase58(src []byte) string {
+	zeros := 0
+	for zeros < len(src) && src[zeros] == 0 {
+		zeros++
+	}
+	// log(256) / log(58), rounded up
+	size := (len(src)-zeros)*138/100 + 1
+	buf := make([]byte, size)
+	high := size - 1
+	for _, b := range src[zeros:] {
+		carry := int(b)
+		i := size - 1
+		for ; i > high || carry != 0; i-- {
+			carry += 256 * int(buf[i])
+			buf[i] = byte(carry % 58)
+			carry /= 58
+		}
+		high = i
+	}
+	start := 0
+	for start < size && buf[start] == 0 {
+		start++
+	}
+	dst := make([]byte, zeros+size-start)
+	for i := 0; i < zeros; i++ {
+		dst[i] = base58Alphabet[0]
+	}
+	for i, b := range buf[start:] {
+		dst[zeros+i] = base58Alphabet[b]
+	}
+	return string(dst)
+}
+
+// decodeBase58 decodes a bitcoin base58 encoded string
+func decodeBase58(src string) ([]byte, error) {
+	zeros := 0
+	for zeros < len(src) && src[zeros] == base58Alphabet[0] {
+		zeros++
+	}
+	// log(58) / log(256), rounded up
+	size := (len(src)-zeros)*733/1000 + 1
+	buf := make([]byte, size)
+	high := size - 1
+	for i := zeros; i < len(src); i++ {
+		carry := base58Index[src[i]]
+		if carry < 0 {
+			return nil, errInvalidBase58
+		}
+		j := size - 1
+		for ; j > high || carry != 0; j-- {
+			carry += 58 * int(buf[j])
+			buf[j] = byte(carry % 256)
+			carry /= 256
+		}
+		high = j
+	}
+	start := 0
+	for start < size && buf[start] == 0 {
+		start++
+	}
+	dst := make([]byte, zeros+size-start)
+	copy(dst[zeros:], buf[start:])
+	return dst, nil
+}
+
+// EncodeBase58 encodes the bytes with the bitcoin base58 alphabet, without a multibase prefix,
+// as used by publicKeyBase58
+func EncodeBase58(src []byte) string {
+	return encodeBase58(src)
+}
+
+// DecodeBase58 decodes a bitcoin base58 encoded string without a multibase prefix
+func DecodeBase58(src string) ([]byte, error) {
+	return decodeBase58(src)
+}
@@ -0,0 +1,124 @@
+// Copyright 2023 The Go SSI Framework Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+package multikey
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+
+	"github.com/decred/dcrd/dcrec/secp256k1/v4"
+	"github.com/lestrrat-go/jwx/v2/x25519"
+)
+
+// BLS12381G2PublicKeySize is the size of a compressed BLS12-381 G2 public key
+const BLS12381G2PublicKeySize int = 96
+
+// BLS12381G2PublicKey is a compressed BLS12-381 G2 public key, as used by BBS+ signatures. The standard
+// library has no BLS12-381 support, so the key is kept as its compressed point.
+type BLS12381G2PublicKey []byte
+
+// EncodePublicKey encodes the public key as a base58btc multibase string
+func EncodePublicKey(key crypto.PublicKey) (string, error) {
+	return EncodePublicKeyWith(Base58BTC, key)
+}
+
+// EncodePublicKeyWith encodes the public key as a multibase string of the base
+func EncodePublicKeyWith(base Base, key crypto.PublicKey) (string, error) {
+	codec, data, err := MarshalPublicKey(key)
+	if err != nil {
+		return "", err
+	}
+	return EncodeWith(base, codec, data)
+}
+
+// DecodePublicKey decodes a multibase string of any supported base into a public key
+func DecodePublicKey(s string) (crypto.PublicKey, error) {
+	codec, data, err := DecodeAny(s)
+	if err != nil {
+		return nil, err
+	}
+	return UnmarshalPublicKey(codec, data)
+}
+
+// MarshalPublicKey returns the codec and the raw key bytes of the public key,
+// elliptic curve keys are marshalled in the compressed form
+func MarshalPublicKey(key crypto.PublicKey) (Codec, []byte, error) {
+	switch k := key.(type) {
+	case ed25519.PublicKey:
+		return Ed25519Pub, []byte(k), nil
+	case *ed25519.PublicKey:
+		return MarshalPublicKey(*k)
+	case x25519.PublicKey:
+		return X25519Pub, []byte(k), nil
+	case *x25519.PublicKey:
+		return MarshalPublicKey(*k)
+	case *ecdsa.PublicKey:
+		switch k.Curve {
+		case elliptic.P256():
+			return P256Pub, elliptic.MarshalCompressed(k.Curve, k.X, k.Y), nil
+		case elliptic.P384():
+			return P384Pub, elliptic.MarshalCompressed(k.Curve, k.X, k.Y), nil
+		case secp256k1.S256():
+			var x, y secp256k1.FieldVal
+			if x.SetByteSlice(k.X.Bytes()) || y.SetByteSlice(k.Y.Bytes()) {
+				return 0, nil, errInvalidKey
+			}
+			return Secp256k1Pub, secp256k1.NewPublicKey(&x, &y).SerializeCompressed(), nil
+		}
+	case ecdsa.PublicKey:
+		return MarshalPublicKey(&k)
+	case BLS12381G2PublicKey:
+		if len(k) != BLS12381G2PublicKeySize {
+			return 0, nil, errInvalidKey
+		}
+		return BLS12381G2Pub, []byte(k), nil
+	}
+	return 0, nil, errUnsupportedKey
+}
+
+// UnmarshalPublicKey converts the raw key bytes of the codec into a public key
+func UnmarshalPublicKey(codec Codec, data []byte) (crypto.PublicKey, error) {
+	switch codec {
+	case Ed25519Pub:
+		if len(data) != ed25519.PublicKeySize {
+			return nil, errInvalidKey
+		}
+		return ed25519.PublicKey(data), nil
+	case X25519Pub:
+		if len(data) != x25519.PublicKeySize {
+			return nil, errInvalidKey
+		}
+		return x25519.PublicKey(data), nil
+	case P256Pub:
+		return unmarshalCompressed(elliptic.P256(), data)
+	case P384Pub:
+		return unmarshalCompressed(elliptic.P384(), data)
+	case Secp256k1Pub:
+		key, err := secp256k1.ParsePubKey(data)
+		if err != nil {
+			return nil, errInvalidKey
+		}
+		return key.ToECDSA(), nil
+	case BLS12381G2Pub:
+		if len(data) != BLS12381G2PublicKeySize {
+			return nil, errInvalidKey
+		}
+		return BLS12381G2PublicKey(data), nil
+	}
+	return nil, errUnsupportedCodec
+}
+
+func unmarshalCompressed(curve elliptic.Curve, data []byte) (crypto.PublicKey, error) {
+	x, y := elliptic.UnmarshalCompressed(curve, data)
+	if x == nil {
+		// also accept the uncompressed form
+		x, y = elliptic.Unmarshal(curve, data)
+		if x == nil {
+			return nil, errInvalidKey
+		}
+	}
+	return &ecdsa.PublicKey{Curve: curve, X: x, Y: y}, nil
+}
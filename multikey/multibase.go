@@ -0,0 +1,91 @@
+// Copyright 2023 The Go SSI Framework Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+package multikey
+
+import (
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"strings"
+)
+
+// Base is the multibase prefix that identifies the encoding of the data
+type Base byte
+
+const (
+	Base16      Base = 'f'
+	Base16Upper Base = 'F'
+	Base58BTC   Base = 'z'
+	Base64URL   Base = 'u'
+)
+
+var (
+	errUnsupportedBase error = errors.New("unsupported_multibase")
+)
+
+// String returns the multibase table name of the base
+func (b Base) String() string {
+	switch b {
+	case Base16:
+		return "base16"
+	case Base16Upper:
+		return "base16upper"
+	case Base58BTC:
+		return "base58btc"
+	case Base64URL:
+		return "base64url"
+	}
+	return "unknown"
+}
+
+// EncodeMultibase encodes the data as a base58btc multibase string, as used for proof values
+func EncodeMultibase(data []byte) string {
+	return string(Base58BTC) + encodeBase58(data)
+}
+
+// EncodeMultibaseWith encodes the data as a multibase string of the base
+func EncodeMultibaseWith(base Base, data []byte) (string, error) {
+	switch base {
+	case Base16:
+		return string(base) + hex.EncodeToString(data), nil
+	case Base16Upper:
+		return string(base) + strings.ToUpper(hex.EncodeToString(data)), nil
+	case Base58BTC:
+		return EncodeMultibase(data), nil
+	case Base64URL:
+		return string(base) + base64.RawURLEncoding.EncodeToString(data), nil
+	}
+	return "", errUnsupportedBase
+}
+
+// DecodeMultibase decodes a base58btc, base64url or base16 multibase string
+func DecodeMultibase(s string) ([]byte, error) {
+	if len(s) < 2 {
+		return nil, errInvalidMultibase
+	}
+	var data []byte
+	var err error
+	switch Base(s[0]) {
+	case Base16:
+		if strings.ToLower(s[1:]) != s[1:] {
+			return nil, errInvalidMultibase
+		}
+		data, err = hex.DecodeString(s[1:])
+	case Base16Upper:
+		if strings.ToUpper(s[1:]) != s[1:] {
+			return nil, errInvalidMultibase
+		}
+		data, err = hex.DecodeString(s[1:])
+	case Base58BTC:
+		data, err = decodeBase58(s[1:])
+	case Base64URL:
+		data, err = base64.RawURLEncoding.DecodeString(s[1:])
+	default:
+		return nil, errInvalidMultibase
+	}
+	if err != nil {
+		return nil, errInvalidMultibase
+	}
+	return data, nil
+}
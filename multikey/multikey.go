@@ -0,0 +1,92 @@
+// Copyright 2023 The Go SSI Framework Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package multikey encodes and decodes public keys in the multibase and multicodec format,
+// as used by publicKeyMultibase and did:key. The base58btc, base64url and base16 multibase
+// encodings are supported.
+package multikey
+
+import (
+	"encoding/binary"
+	"errors"
+)
+
+// Codec is a multicodec code that identifies the type of the encoded data
+type Codec uint64
+
+const (
+	SHA256        Codec = 0x12
+	JSON          Codec = 0x0200
+	Ed25519Pub    Codec = 0xed
+	X25519Pub     Codec = 0xec
+	Secp256k1Pub  Codec = 0xe7
+	P256Pub       Codec = 0x1200
+	P384Pub       Codec = 0x1201
+	BLS12381G2Pub Codec = 0xeb
+)
+
+var (
+	errInvalidMultibase error = errors.New("invalid_multibase")
+	errInvalidCodec     error = errors.New("invalid_multicodec")
+	errUnsupportedCodec error = errors.New("unsupported_multicodec")
+	errUnsupportedKey   error = errors.New("unsupported_key_type")
+	errInvalidKey       error = errors.New("invalid_key")
+)
+
+// String returns the multicodec table name of the codec
+func (c Codec) String() string {
+	switch c {
+	case SHA256:
+		return "sha2-256"
+	case JSON:
+		return "json"
+	case Ed25519Pub:
+		return "ed25519-pub"
+	case X25519Pub:
+		return "x25519-pub"
+	case Secp256k1Pub:
+		return "secp256k1-pub"
+	case P256Pub:
+		return "p256-pub"
+	case P384Pub:
+		return "p384-pub"
+	case BLS12381G2Pub:
+		return "bls12_381-g2-pub"
+	}
+	return "unknown"
+}
+
+// Encode prefixes the key bytes with the codec and encodes it as a base58btc multibase string
+func Encode(codec Codec, key []byte) string {
+	prefix := binary.AppendUvarint(nil, uint64(codec))
+	return EncodeMultibase(append(prefix, key...))
+}
+
+// EncodeWith prefixes the key bytes with the codec and encodes it as a multibase string of the base
+func EncodeWith(base Base, codec Codec, key []byte) (string, error) {
+	prefix := binary.AppendUvarint(nil, uint64(codec))
+	return EncodeMultibaseWith(base, append(prefix, key...))
+}
+
+// Decode decodes a base58btc multibase string into the codec and the key bytes, the canonical encoding of
+// did:key and did:peer. Other bases are rejected, as they would be aliases of the same key.
+func Decode(s string) (Codec, []byte, error) {
+	if len(s) == 0 || Base(s[0]) != Base58BTC {
+		return 0, nil, errInvalidMultibase
+	}
+	return DecodeAny(s)
+}
+
+// DecodeAny decodes a multibase string of any supported base into the codec and the key bytes
+func DecodeAny(s string) (Codec, []byte, error) {
+	data, err := DecodeMultibase(s)
+	if err != nil {
+		return 0, nil, err
+	}
+	code, n := binary.Uvarint(data)
+	if n <= 0 {
+		return 0, nil, errInvalidCodec
+	}
+	return Codec(code), data[n:], nil
+}
@@ -0,0 +1,121 @@
+// Copyright 2023 The Go SSI Framework Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+package multikey_test
+
+import (
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rand"
+	"testing"
+
+	"github.com/decred/dcrd/dcrec/secp256k1/v4"
+	"github.com/gossif/diddoc/multikey"
+	"github.com/lestrrat-go/jwx/v2/x25519"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDecode(t *testing.T) {
+	type errorTestCases struct {
+		description   string
+		input         string
+		expectedCodec multikey.Codec
+		expectedSize  int
+		expectedError string
+	}
+	for _, scenario := range []errorTestCases{
+		{description: "ed25519", input: "z6MkhaXgBZDvotDkL5257faiztiGiC2QtKLGpbnnEGta2doK", expectedCodec: multikey.Ed25519Pub, expectedSize: 32},
+		{description: "x25519", input: "z6LSj72tK8brWgZja8NLRwPigth2T9QRiG1uH9oKZuKjdh9p", expectedCodec: multikey.X25519Pub, expectedSize: 32},
+		{description: "p-256", input: "zDnaerDaTF5BXEavCrfRZEk316dpbLsfPDZ3WJ5hRTPFU2169", expectedCodec: multikey.P256Pub, expectedSize: 33},
+		{description: "p-384", input: "z82Lm1MpAkeJcix9K8TMiLd5NMAhnwkjjCBeWHXyu3U4oT2MVJJKXkcVBgjGhnLBn2Kaau9", expectedCodec: multikey.P384Pub, expectedSize: 49},
+		{description: "secp256k1", input: "zQ3shokFTS3brHcDQrn82RUDfCZESWL1ZdCEJwekUDPQiYBme", expectedCodec: multikey.Secp256k1Pub, expectedSize: 33},
+		{description: "no multibase prefix", input: "6MkhaXgBZDvotDkL5257faiztiGiC2QtKLGpbnnEGta2doK", expectedError: "invalid_multibase"},
+		{description: "invalid base58", input: "z6MkhaXgBZDvotDkL5257faiztiGiC2QtKLGpbnnEGta2do0", expectedError: "invalid_multibase"},
+		{description: "base64url", input: "u7QEub8zjZwHceRSI4NCxdFzB4zpMHJ_MQcY700Pbvglw5g", expectedError: "invalid_multibase"},
+		{description: "base16", input: "fed012e6fcce36701dc791488e0d0b1745cc1e33a4c1c9fcc41c63bd343dbbe0970e6", expectedError: "invalid_multibase"},
+	} {
+		t.Run(scenario.description, func(t *testing.T) {
+
+			codec, data, err := multikey.Decode(scenario.input)
+			if scenario.expectedError != "" {
+				assert.ErrorContains(t, err, scenario.expectedError)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, scenario.expectedCodec, codec)
+			assert.Len(t, data, scenario.expectedSize)
+			assert.Equal(t, scenario.input, multikey.Encode(codec, data))
+
+			_, err = multikey.UnmarshalPublicKey(codec, data)
+			assert.NoError(t, err)
+		})
+	}
+}
+
+func TestPublicKeyRoundTrip(t *testing.T) {
+	edKey, _, _ := ed25519.GenerateKey(rand.Reader)
+	xKey, _, _ := x25519.GenerateKey(rand.Reader)
+	p256Key, _ := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	p384Key, _ := ecdsa.GenerateKey(elliptic.P384(), rand.Reader)
+	k256Key, _ := secp256k1.GeneratePrivateKey()
+
+	for description, pubKey := range map[string]interface{}{
+		"ed25519":   edKey,
+		"x25519":    xKey,
+		"p-256":     &p256Key.PublicKey,
+		"p-384":     &p384Key.PublicKey,
+		"secp256k1": k256Key.PubKey().ToECDSA(),
+	} {
+		t.Run(description, func(t *testing.T) {
+			encoded, err := multikey.EncodePublicKey(pubKey)
+			require.NoError(t, err)
+
+			decoded, err := multikey.DecodePublicKey(encoded)
+			require.NoError(t, err)
+			assert.Equal(t, pubKey, decoded)
+		})
+	}
+}
+
+func TestMultibase(t *testing.T) {
+	edKey, _, _ := ed25519.GenerateKey(rand.Reader)
+	blsKey := make(multikey.BLS12381G2PublicKey, multikey.BLS12381G2PublicKeySize)
+	_, _ = rand.Read(blsKey)
+
+	for _, base := range []multikey.Base{multikey.Base58BTC, multikey.Base64URL, multikey.Base16, multikey.Base16Upper} {
+		for description, pubKey := range map[string]interface{}{
+			"ed25519":          edKey,
+			"bls12_381-g2-pub": blsKey,
+		} {
+			t.Run(base.String()+"/"+description, func(t *testing.T) {
+				encoded, err := multikey.EncodePublicKeyWith(base, pubKey)
+				require.NoError(t, err)
+				assert.Equal(t, byte(base), encoded[0])
+
+				decoded, err := multikey.DecodePublicKey(encoded)
+				require.NoError(t, err)
+				assert.Equal(t, pubKey, decoded)
+			})
+		}
+	}
+	t.Run("unsupported base", func(t *testing.T) {
+		_, err := multikey.EncodePublicKeyWith(multikey.Base('m'), edKey)
+		assert.ErrorContains(t, err, "unsupported_multibase")
+
+		_, err = multikey.DecodeMultibase("mAQID")
+		assert.ErrorContains(t, err, "invalid_multibase")
+	})
+	t.Run("any base", func(t *testing.T) {
+		for _, encoded := range []string{"u7QEub8zjZwHceRSI4NCxdFzB4zpMHJ_MQcY700Pbvglw5g", "fed012e6fcce36701dc791488e0d0b1745cc1e33a4c1c9fcc41c63bd343dbbe0970e6"} {
+			codec, data, err := multikey.DecodeAny(encoded)
+			require.NoError(t, err)
+			assert.Equal(t, "z6MkhaXgBZDvotDkL5257faiztiGiC2QtKLGpbnnEGta2doK", multikey.Encode(codec, data))
+		}
+	})
+	t.Run("mixed case base16", func(t *testing.T) {
+		_, err := multikey.DecodeMultibase("fED01aB")
+		assert.ErrorContains(t, err, "invalid_multibase")
+	})
+}
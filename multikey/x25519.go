@@ -0,0 +1,50 @@
+// Copyright 2023 The Go SSI Framework Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+package multikey
+
+import (
+	"crypto/ed25519"
+	"errors"
+	"math/big"
+
+	"github.com/lestrrat-go/jwx/v2/x25519"
+)
+
+var (
+	errInvalidEd25519Key error = errors.New("invalid_ed25519_key")
+
+	// curve25519P is the prime 2^255 - 19 of the curve25519 field
+	curve25519P = new(big.Int).Sub(new(big.Int).Lsh(big.NewInt(1), 255), big.NewInt(19))
+)
+
+// Ed25519ToX25519 converts the Ed25519 public key into the X25519 public key used for key agreement. The Edwards
+// point is converted to the birationally equivalent Montgomery point, u = (1 + y) / (1 - y)
+func Ed25519ToX25519(key ed25519.PublicKey) (x25519.PublicKey, error) {
+	if len(key) != ed25519.PublicKeySize {
+		return nil, errInvalidEd25519Key
+	}
+	// the point is encoded little-endian with the sign of x in the most significant bit
+	le := make([]byte, len(key))
+	for i := range key {
+		le[len(key)-1-i] = key[i]
+	}
+	le[0] &= 0x7f
+	y := new(big.Int).SetBytes(le)
+
+	denominator := new(big.Int).Sub(big.NewInt(1), y)
+	denominator.Mod(denominator, curve25519P)
+	if denominator.Sign() == 0 {
+		return nil, errInvalidEd25519Key
+	}
+	u := new(big.Int).Add(big.NewInt(1), y)
+	u.Mul(u, denominator.ModInverse(denominator, curve25519P))
+	u.Mod(u, curve25519P)
+
+	be := u.FillBytes(make([]byte, x25519.PublicKeySize))
+	out := make(x25519.PublicKey, x25519.PublicKeySize)
+	for i := range be {
+		out[len(be)-1-i] = be[i]
+	}
+	return out, nil
+}
@@ -0,0 +1,230 @@
+// Copyright 2023 The Go SSI Framework Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+package diddoc
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/gossif/diddoc/internal/jwkutil"
+)
+
+var (
+	errDuplicateId error = errors.New("duplicate_id")
+
+	// verificationRelationshipKeys are the properties that reference or embed verification methods
+	verificationRelationshipKeys = []string{
+		authenticationKey,
+		assertionMethodKey,
+		keyAgreementKey,
+		capabilityInvocationKey,
+		capabilityDelegationKey,
+	}
+)
+
+// Has reports whether the document has the property with a key
+func (d *Document) Has(key string) bool {
+	defer d.rlock()()
+	return d.has(key)
+}
+
+func (d *Document) has(key string) bool {
+	for _, prop := range d.properties {
+		if prop.Key == key {
+			return true
+		}
+	}
+	return false
+}
+
+// Keys returns the keys of the properties, in the order in which they were set
+func (d *Document) Keys() []string {
+	defer d.rlock()()
+
+	var keys []string
+	for _, prop := range d.properties {
+		if key, ok := prop.Key.(string); ok {
+			keys = append(keys, key)
+		}
+	}
+	return keys
+}
+
+// Replace replaces the value of an existing property with a key
+func (d *Document) Replace(key string, value interface{}) error {
+	defer d.lock()()
+	if d.readOnly {
+		return errReadOnly
+	}
+	if !d.has(key) {
+		return fmt.Errorf("%w: property %q", errNotFound, key)
+	}
+	d.set(key, value)
+	return nil
+}
+
+// Delete deletes the property with a key
+func (d *Document) Delete(key string) error {
+	defer d.lock()()
+	if d.readOnly {
+		return errReadOnly
+	}
+	return d.delete(key)
+}
+
+func (d *Document) delete(key string) error {
+	delete(d.scalars, key)
+	for i, prop := range d.properties {
+		if prop.Key == key {
+			d.properties = append(d.properties[:i:i], d.properties[i+1:]...)
+			return nil
+		}
+	}
+	return fmt.Errorf("%w: property %q", errNotFound, key)
+}
+
+// AddVerificationMethod adds the verification method to the document, and references it from the verification
+// relationships of the purposes. The id of the verification method must be unique within the document, including
+// the verification methods that are embedded in a relationship.
+func (d *Document) AddVerificationMethod(verificationMethod VerificationMethod, purposes ...ProofPurpose) error {
+	if verificationMethod.PubicKeyJWK != nil && jwkutil.IsPrivate(verificationMethod.PubicKeyJWK) {
+		return errPrivateKeyNotAllowed
+	}
+	defer d.lock()()
+	if d.readOnly {
+		return errReadOnly
+	}
+	if _, err := d.findVerificationMethod(verificationMethod.Id); err == nil {
+		return fmt.Errorf("%w: verification method %q", errDuplicateId, verificationMethod.Id)
+	}
+	var verificationMethods []VerificationMethod
+	if err := encode(&verificationMethods, d.get(verificationMethodKey)); err != nil {
+		return err
+	}
+	d.set(verificationMethodKey, append(verificationMethods, verificationMethod))
+
+	for _, purpose := range purposes {
+		var relations []VerificationRelation
+		if err := encode(&relations, d.get(purpose.String())); err != nil {
+			return err
+		}
+		d.set(purpose.String(), append(relations, verificationMethod.Id))
+	}
+	return nil
+}
+
+// RemoveVerificationMethod removes the verification method with the id, the references to it and the embedded
+// verification methods with the same id are pruned from the verification relationships
+func (d *Document) RemoveVerificationMethod(keyId string) error {
+	defer d.lock()()
+	if d.readOnly {
+		return errReadOnly
+	}
+	subject := d.subject()
+	keyId = normalizeDIDURL(keyId, subject)
+
+	var verificationMethods []VerificationMethod
+	if err := encode(&verificationMethods, d.get(verificationMethodKey)); err != nil {
+		return err
+	}
+	var remaining []VerificationMethod
+	for _, verificationMethod := range verificationMethods {
+		if normalizeDIDURL(verificationMethod.Id, subject) != keyId {
+			remaining = append(remaining, verificationMethod)
+		}
+	}
+	removed := len(remaining) < len(verificationMethods)
+	if removed {
+		d.setOrDelete(verificationMethodKey, remaining, len(remaining))
+	}
+	for _, key := range verificationRelationshipKeys {
+		pruned, err := d.pruneRelationship(key, keyId, subject)
+		if err != nil {
+			return err
+		}
+		removed = removed || pruned
+	}
+	if !removed {
+		return fmt.Errorf("%w: verification method %q", errNotFound, keyId)
+	}
+	return nil
+}
+
+// pruneRelationship removes the references and embedded verification methods with the id from the relationship
+func (d *Document) pruneRelationship(key string, keyId string, subject DID) (bool, error) {
+	if !d.has(key) {
+		return false, nil
+	}
+	var relations []VerificationRelation
+	if err := encode(&relations, d.get(key)); err != nil {
+		return false, err
+	}
+	var remaining []VerificationRelation
+	for _, relation := range relations {
+		if relationId(relation, subject) != keyId {
+			remaining = append(remaining, relation)
+		}
+	}
+	if len(remaining) == len(relations) {
+		return false, nil
+	}
+	d.setOrDelete(key, remaining, len(remaining))
+	return true, nil
+}
+
+// AddService adds the service to the document, the id of the service must be unique within the document
+func (d *Document) AddService(service Service) error {
+	defer d.lock()()
+	if d.readOnly {
+		return errReadOnly
+	}
+	subject := d.subject()
+
+	var services []Service
+	if err := encode(&services, d.get(serviceKey)); err != nil {
+		return err
+	}
+	for _, s := range services {
+		if normalizeDIDURL(s.Id, subject) == normalizeDIDURL(service.Id, subject) {
+			return fmt.Errorf("%w: service %q", errDuplicateId, service.Id)
+		}
+	}
+	d.set(serviceKey, append(services, service))
+	return nil
+}
+
+// RemoveService removes the service with the id
+func (d *Document) RemoveService(id string) error {
+	defer d.lock()()
+	if d.readOnly {
+		return errReadOnly
+	}
+	subject := d.subject()
+	id = normalizeDIDURL(id, subject)
+
+	var services []Service
+	if err := encode(&services, d.get(serviceKey)); err != nil {
+		return err
+	}
+	var remaining []Service
+	for _, s := range services {
+		if normalizeDIDURL(s.Id, subject) != id {
+			remaining = append(remaining, s)
+		}
+	}
+	if len(remaining) == len(services) {
+		return fmt.Errorf("%w: service %q", errNotFound, id)
+	}
+	d.setOrDelete(serviceKey, remaining, len(remaining))
+	return nil
+}
+
+// setOrDelete sets the set of values of the property, the property is deleted when the set is empty
+func (d *Document) setOrDelete(key string, values interface{}, n int) {
+	if n == 0 {
+		_ = d.delete(key)
+		return
+	}
+	d.set(key, values)
+}
@@ -0,0 +1,87 @@
+// Copyright 2023 The Go SSI Framework Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+package diddoc_test
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/gossif/diddoc"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const mutationDocument string = `{"@context":"https://www.w3.org/ns/did/v1","id":"did:example:123","verificationMethod":[{"id":"did:example:123#key-1","type":"Multikey","controller":"did:example:123","publicKeyMultibase":"z6MkhaXgBZDvotDkL5257faiztiGiC2QtKLGpbnnEGta2doK"}],"authentication":["#key-1",{"id":"did:example:123#key-2","type":"Multikey","controller":"did:example:123","publicKeyMultibase":"z6MkhaXgBZDvotDkL5257faiztiGiC2QtKLGpbnnEGta2doK"}],"assertionMethod":["did:example:123#key-1"],"service":[{"id":"#linked-domain","type":"LinkedDomains","serviceEndpoint":"https://bar.example.com"}]}`
+
+func TestDocumentProperties(t *testing.T) {
+	doc := diddoc.NewDocument()
+	require.NoError(t, json.Unmarshal([]byte(mutationDocument), doc))
+
+	assert.Equal(t, []string{"@context", "id", "verificationMethod", "authentication", "assertionMethod", "service"}, doc.Keys())
+	assert.True(t, doc.Has("service"))
+
+	require.NoError(t, doc.Set("alsoKnownAs", []string{"https://example.com"}))
+	require.NoError(t, doc.Set("alsoKnownAs", []string{"https://example.org"}))
+	assert.Equal(t, []string{"https://example.org"}, doc.AlsoKnownAs())
+	assert.Len(t, doc.Keys(), 7)
+
+	require.NoError(t, doc.Replace("alsoKnownAs", []string{"https://example.net"}))
+	assert.Equal(t, []string{"https://example.net"}, doc.AlsoKnownAs())
+	assert.ErrorContains(t, doc.Replace("custom", "value"), "not_found")
+
+	require.NoError(t, doc.Delete("alsoKnownAs"))
+	assert.False(t, doc.Has("alsoKnownAs"))
+	assert.ErrorContains(t, doc.Delete("alsoKnownAs"), "not_found")
+}
+
+func TestVerificationMethodMutation(t *testing.T) {
+	doc := diddoc.NewDocument()
+	require.NoError(t, json.Unmarshal([]byte(mutationDocument), doc))
+
+	rotated := diddoc.VerificationMethod{Id: "did:example:123#key-3", Type: diddoc.MultikeyType, Controller: "did:example:123", PublicKeyMultibase: "zDnaerDaTF5BXEavCrfRZEk316dpbLsfPDZ3WJ5hRTPFU2169"}
+	require.NoError(t, doc.AddVerificationMethod(rotated, diddoc.Authentication, diddoc.AssertionMethod))
+	assert.ErrorContains(t, doc.AddVerificationMethod(rotated), "duplicate_id")
+	// the id of a verification method that is embedded in a relationship is taken as well
+	embedded := rotated
+	embedded.Id = "#key-2"
+	assert.ErrorContains(t, doc.AddVerificationMethod(embedded), "duplicate_id")
+
+	require.NoError(t, doc.RemoveVerificationMethod("#key-1"))
+	_, err := doc.GetVerificationMethodById("#key-1")
+	assert.ErrorContains(t, err, "not_found")
+
+	authentication, err := doc.GetAssociatedVerificationMethod(diddoc.Authentication)
+	require.NoError(t, err)
+	require.Len(t, authentication, 2)
+	assert.Equal(t, "did:example:123#key-2", authentication[0].Id)
+	assert.Equal(t, rotated, authentication[1])
+
+	assertionMethod, err := doc.GetAssociatedVerificationMethod(diddoc.AssertionMethod)
+	require.NoError(t, err)
+	assert.Equal(t, []diddoc.VerificationMethod{rotated}, assertionMethod)
+
+	// the embedded verification method is pruned as well
+	require.NoError(t, doc.RemoveVerificationMethod("did:example:123#key-2"))
+	authentication, err = doc.GetAssociatedVerificationMethod(diddoc.Authentication)
+	require.NoError(t, err)
+	assert.Equal(t, []diddoc.VerificationMethod{rotated}, authentication)
+
+	assert.ErrorContains(t, doc.RemoveVerificationMethod("#key-1"), "not_found")
+}
+
+func TestServiceMutation(t *testing.T) {
+	doc := diddoc.NewDocument()
+	require.NoError(t, json.Unmarshal([]byte(mutationDocument), doc))
+
+	service := diddoc.Service{Id: "did:example:123#didcomm", Type: diddoc.DIDCommMessagingType, ServiceEndpoint: diddoc.URIServiceEndpoint("https://example.com/didcomm")}
+	require.NoError(t, doc.AddService(service))
+	assert.ErrorContains(t, doc.AddService(service), "duplicate_id")
+
+	require.NoError(t, doc.RemoveService("did:example:123#linked-domain"))
+	assert.Equal(t, []diddoc.Service{service}, doc.Services())
+
+	require.NoError(t, doc.RemoveService("#didcomm"))
+	assert.False(t, doc.Has("service"))
+	assert.ErrorContains(t, doc.RemoveService("#didcomm"), "not_found")
+}
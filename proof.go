@@ -0,0 +1,395 @@
+// Copyright 2023 The Go SSI Framework Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+package diddoc
+
+import (
+	"bytes"
+	"context"
+	"crypto"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/gossif/diddoc/multikey"
+	"github.com/piprate/json-gold/ld"
+)
+
+const (
+	// DataIntegrityProofType is the proof type of the Data Integrity cryptosuites
+	DataIntegrityProofType string = "DataIntegrityProof"
+
+	proofKey string = "proof"
+)
+
+var (
+	errProofNotFound         error = errors.New("proof_not_found")
+	errUnsupportedProofType  error = errors.New("unsupported_proof_type")
+	errMissingMethod         error = errors.New("missing_verification_method")
+	errMissingPurpose        error = errors.New("missing_proof_purpose")
+	errNotAuthorized         error = errors.New("not_authorized")
+	errControllerNotResolved error = errors.New("controller_not_resolved")
+	errDomainMismatch        error = errors.New("domain_mismatch")
+	errChallengeMismatch     error = errors.New("challenge_mismatch")
+	errInvalidProof          error = errors.New("invalid_proof")
+
+	// defaultDocumentLoader only loads the bundled JSON-LD contexts, fetching remote contexts is opt-in
+	defaultDocumentLoader ld.DocumentLoader = StaticDocumentLoader(nil)
+)
+
+// ProofOptions are the options of a Data Integrity or a JsonWebSignature2020 proof
+type ProofOptions struct {
+	// Cryptosuite is the Data Integrity cryptosuite, e.g. eddsa-jcs-2022, it is not used by JsonWebSignature2020
+	Cryptosuite string
+	// VerificationMethod is the DID URL of the verification method of the signer
+	VerificationMethod string
+	// ProofPurpose is the verification relationship of the verification method, assertionMethod by default
+	ProofPurpose ProofPurpose
+	// Created is the creation time of the proof, the current time by default
+	Created   time.Time
+	Domain    string
+	Challenge string
+	Nonce     string
+	// DocumentLoader loads the JSON-LD contexts of the RDF canonicalization, by default only the bundled contexts are
+	// loaded, see StaticDocumentLoader and RemoteDocumentLoader
+	DocumentLoader ld.DocumentLoader
+}
+
+// VerifyOption is an option of the proof verification
+type VerifyOption func(*verifyOptions)
+
+type verifyOptions struct {
+	loader    ld.DocumentLoader
+	domain    string
+	challenge string
+}
+
+// WithDocumentLoader sets the loader of the JSON-LD contexts of the RDF canonicalization
+func WithDocumentLoader(loader ld.DocumentLoader) VerifyOption {
+	return func(o *verifyOptions) {
+		o.loader = loader
+	}
+}
+
+// WithDomain sets the domain that the proofs must have
+func WithDomain(domain string) VerifyOption {
+	return func(o *verifyOptions) {
+		o.domain = domain
+	}
+}
+
+// WithChallenge sets the challenge that the proofs must have
+func WithChallenge(challenge string) VerifyOption {
+	return func(o *verifyOptions) {
+		o.challenge = challenge
+	}
+}
+
+// Sign adds a Data Integrity proof to the document, signed by the signer. A document that already has a proof
+// gets a proof set, every proof of the set is over the document without the proofs. The RDF cryptosuites add
+// the Data Integrity context to the document when it is absent.
+func (d *Document) Sign(signer crypto.Signer, opts ProofOptions) error {
+	if d.ReadOnly() {
+		return errReadOnly
+	}
+	if suite, ok := cryptosuites[opts.Cryptosuite]; ok && suite.rdfc {
+		if err := d.addContext(ContextDataIntegrityV2); err != nil {
+			return err
+		}
+	}
+	document, err := d.unsecuredJSON()
+	if err != nil {
+		return err
+	}
+	proof, err := CreateDataIntegrityProof(document, signer, opts)
+	if err != nil {
+		return err
+	}
+	return d.addProof(proof)
+}
+
+// CreateDataIntegrityProof creates a Data Integrity proof of the JSON-LD payload, which must not contain the proof
+func CreateDataIntegrityProof(payload []byte, signer crypto.Signer, opts ProofOptions) (Proof, error) {
+	suite, ok := cryptosuites[opts.Cryptosuite]
+	if !ok {
+		return Proof{}, errUnsupportedCryptosuite
+	}
+	if opts.VerificationMethod == "" {
+		return Proof{}, errMissingMethod
+	}
+	hash, err := suite.keyHash(signer.Public())
+	if err != nil {
+		return Proof{}, err
+	}
+	proof := newProof(DataIntegrityProofType, opts)
+	proof.Cryptosuite = opts.Cryptosuite
+	config, err := proofConfig(proof, payload)
+	if err != nil {
+		return Proof{}, err
+	}
+	hashData, err := suite.hashData(payload, config, hash, documentLoader(opts.DocumentLoader))
+	if err != nil {
+		return Proof{}, err
+	}
+	signature, err := suite.sign(signer, hash, hashData)
+	if err != nil {
+		return Proof{}, err
+	}
+	proof.ProofValue = multikey.EncodeMultibase(signature)
+	return proof, nil
+}
+
+// VerifyDataIntegrityProof verifies the Data Integrity proof of the JSON-LD payload, which must not contain the
+// proof. The verification method must be authorized by the controller document as in VerifyProof.
+func VerifyDataIntegrityProof(payload []byte, proof Proof, controller *Document, resolver Resolver, opts ...VerifyOption) error {
+	options := verifyOptions{loader: defaultDocumentLoader}
+	for _, opt := range opts {
+		opt(&options)
+	}
+	if proof.Type != DataIntegrityProofType {
+		return errUnsupportedProofType
+	}
+	if err := options.check(proof); err != nil {
+		return err
+	}
+	verificationMethod, err := controller.authorize(proof.VerificationMethod, proof.ProofPurpose, lookupOptions{ctx: context.Background(), resolver: resolver})
+	if err != nil {
+		return err
+	}
+	key, err := verificationMethod.PublicKey()
+	if err != nil {
+		return err
+	}
+	return verifyDataIntegrity(payload, proof, key, options.loader)
+}
+
+// VerifyProof verifies the proofs of the document. The verification method of a proof must be authorized for the
+// proof purpose, as reported by IsAuthorized. The DID documents of other DIDs are resolved by the resolver.
+func (d *Document) VerifyProof(resolver Resolver, opts ...VerifyOption) error {
+	options := verifyOptions{loader: defaultDocumentLoader}
+	for _, opt := range opts {
+		opt(&options)
+	}
+	proofs, err := d.Proofs()
+	if err != nil {
+		return err
+	}
+	if len(proofs) == 0 {
+		return errProofNotFound
+	}
+	for _, proof := range proofs {
+		if err := d.verifyProof(resolver, proof, options); err != nil {
+			return fmt.Errorf("proof of %s: %w", proof.VerificationMethod, err)
+		}
+	}
+	return nil
+}
+
+// Proofs gets the proofs of the document, the proof property is a proof or a proof set
+func (d *Document) Proofs() ([]Proof, error) {
+	value := d.Get(proofKey)
+	if value == nil {
+		return nil, nil
+	}
+	data, err := json.Marshal(value)
+	if err != nil {
+		return nil, err
+	}
+	var proofs []Proof
+	if bytes.HasPrefix(bytes.TrimSpace(data), []byte("[")) {
+		err = json.Unmarshal(data, &proofs)
+	} else {
+		var proof Proof
+		err = json.Unmarshal(data, &proof)
+		proofs = append(proofs, proof)
+	}
+	if err != nil {
+		return nil, errInvalidProof
+	}
+	return proofs, nil
+}
+
+func (d *Document) verifyProof(resolver Resolver, proof Proof, options verifyOptions) error {
+	if proof.Type != DataIntegrityProofType && proof.Type != JsonWebSignature2020Type {
+		return errUnsupportedProofType
+	}
+	if err := options.check(proof); err != nil {
+		return err
+	}
+	verificationMethod, err := d.authorize(proof.VerificationMethod, proof.ProofPurpose, lookupOptions{ctx: context.Background(), resolver: resolver})
+	if err != nil {
+		return err
+	}
+	key, err := verificationMethod.PublicKey()
+	if err != nil {
+		return err
+	}
+	document, err := d.unsecuredJSON()
+	if err != nil {
+		return err
+	}
+	if proof.Type == JsonWebSignature2020Type {
+		return verifyJWS2020(document, proof, key, options.loader)
+	}
+	return verifyDataIntegrity(document, proof, key, options.loader)
+}
+
+func verifyDataIntegrity(document []byte, proof Proof, key crypto.PublicKey, loader ld.DocumentLoader) error {
+	suite, ok := cryptosuites[proof.Cryptosuite]
+	if !ok {
+		return errUnsupportedCryptosuite
+	}
+	hash, err := suite.keyHash(key)
+	if err != nil {
+		return err
+	}
+	// the proof value of the Data Integrity cryptosuites is base58btc encoded
+	if len(proof.ProofValue) == 0 || multikey.Base(proof.ProofValue[0]) != multikey.Base58BTC {
+		return errInvalidProof
+	}
+	signature, err := multikey.DecodeMultibase(proof.ProofValue)
+	if err != nil {
+		return errInvalidProof
+	}
+	config, err := proofConfig(proof, document)
+	if err != nil {
+		return err
+	}
+	hashData, err := suite.hashData(document, config, hash, loader)
+	if err != nil {
+		return err
+	}
+	return suite.verify(key, hash, hashData, signature)
+}
+
+// check checks the domain and the challenge of the proof
+func (o verifyOptions) check(proof Proof) error {
+	if o.domain != "" && proof.Domain != o.domain {
+		return errDomainMismatch
+	}
+	if o.challenge != "" && proof.Challenge != o.challenge {
+		return errChallengeMismatch
+	}
+	return nil
+}
+
+// newProof creates a proof of the type with the options, the creation time is truncated to seconds
+func newProof(proofType string, opts ProofOptions) Proof {
+	proof := Proof{
+		Type:               proofType,
+		Created:            opts.Created,
+		VerificationMethod: opts.VerificationMethod,
+		ProofPurpose:       opts.ProofPurpose,
+		Domain:             opts.Domain,
+		Challenge:          opts.Challenge,
+		Nonce:              opts.Nonce,
+	}
+	if proof.ProofPurpose == "" {
+		proof.ProofPurpose = AssertionMethod
+	}
+	if proof.Created.IsZero() {
+		proof.Created = time.Now()
+	}
+	proof.Created = proof.Created.UTC().Truncate(time.Second)
+	return proof
+}
+
+func documentLoader(loader ld.DocumentLoader) ld.DocumentLoader {
+	if loader == nil {
+		return defaultDocumentLoader
+	}
+	return loader
+}
+
+// unsecuredJSON returns the JSON representation of the document without the proofs
+func (d *Document) unsecuredJSON() ([]byte, error) {
+	defer d.rlock()()
+
+	unsecured := NewDocument()
+	for _, prop := range d.properties {
+		if prop.Key != proofKey {
+			unsecured.properties = append(unsecured.properties, prop)
+		}
+	}
+	return unsecured.MarshalJSON()
+}
+
+// proofConfig returns the proof without the signature in the context of the JSON-LD document. The members of a
+// decoded proof that the proof does not model, such as expires or previousProof, are part of the configuration,
+// and its created timestamp is kept as received.
+func proofConfig(proof Proof, document []byte) ([]byte, error) {
+	config, err := json.Marshal(proof)
+	if err != nil {
+		return nil, err
+	}
+	var configProperties map[string]interface{}
+	if err := json.Unmarshal(config, &configProperties); err != nil {
+		return nil, err
+	}
+	for key, value := range proof.members {
+		if _, ok := configProperties[key]; !ok || key == "created" && sameTime(value, proof.Created) {
+			configProperties[key] = value
+		}
+	}
+	delete(configProperties, "proofValue")
+	delete(configProperties, "jws")
+	// the context is taken from the JSON representation, so a single context is a string like in the document
+	var properties map[string]interface{}
+	if err := json.Unmarshal(document, &properties); err != nil {
+		return nil, err
+	}
+	if context, ok := properties[contextKey]; ok {
+		configProperties[contextKey] = context
+	}
+	return json.Marshal(configProperties)
+}
+
+// sameTime reports whether the value is a timestamp of the time t
+func sameTime(value interface{}, t time.Time) bool {
+	s, ok := value.(string)
+	if !ok {
+		return false
+	}
+	parsed, err := time.Parse(time.RFC3339, s)
+	return err == nil && parsed.Equal(t)
+}
+
+// addContext adds the context to the @context property, unless it is already present
+func (d *Document) addContext(context string) error {
+	defer d.lock()()
+	if d.readOnly {
+		return errReadOnly
+	}
+	items, _ := elements(d.get(contextKey))
+	for _, c := range items {
+		if c == context {
+			return nil
+		}
+	}
+	var contexts []string
+	if err := encode(&contexts, items); err != nil {
+		// the embedded contexts are kept as they are
+		d.set(contextKey, append(items, context))
+		return nil
+	}
+	d.set(contextKey, append(contexts, context))
+	return nil
+}
+
+// addProof adds the proof to the proof property, an existing proof becomes a proof set
+func (d *Document) addProof(proof Proof) error {
+	defer d.lock()()
+	if d.readOnly {
+		return errReadOnly
+	}
+	switch existing := d.get(proofKey).(type) {
+	case nil:
+		d.set(proofKey, proof)
+	case []interface{}:
+		d.set(proofKey, append(existing, proof))
+	default:
+		d.set(proofKey, []interface{}{existing, proof})
+	}
+	return nil
+}
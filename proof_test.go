@@ -0,0 +1,365 @@
+// Copyright 2023 The Go SSI Framework Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+package diddoc_test
+
+import (
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/gossif/diddoc"
+	"github.com/gossif/diddoc/didkey"
+	"github.com/gossif/diddoc/multikey"
+	"github.com/lestrrat-go/jwx/v2/jwk"
+	"github.com/piprate/json-gold/ld"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// testDocumentLoader serves the contexts without network access, the terms are mapped with a vocabulary
+type testDocumentLoader struct{}
+
+func (testDocumentLoader) LoadDocument(u string) (*ld.RemoteDocument, error) {
+	switch u {
+	case diddoc.ContextDIDv1, diddoc.ContextMultikeyV1, diddoc.ContextJWS2020V1, diddoc.ContextDataIntegrityV2:
+		return &ld.RemoteDocument{DocumentURL: u, Document: map[string]interface{}{
+			"@context": map[string]interface{}{
+				"@vocab":             "https://w3id.org/security#",
+				"id":                 "@id",
+				"type":               "@type",
+				"verificationMethod": map[string]interface{}{"@type": "@id"},
+				"controller":         map[string]interface{}{"@type": "@id"},
+				"publicKeyJwk":       map[string]interface{}{"@type": "@json"},
+				"created":            map[string]interface{}{"@type": "http://www.w3.org/2001/XMLSchema#dateTime"},
+			},
+		}}, nil
+	}
+	return nil, errors.New("unknown context " + u)
+}
+
+func signingDocument(t *testing.T, verificationMethod diddoc.VerificationMethod) *diddoc.Document {
+	doc, err := diddoc.NewBuilder().
+		Context([]string{diddoc.ContextDIDv1, diddoc.ContextMultikeyV1}).
+		Subject("did:example:123").
+		VerificationMethod(verificationMethod).
+		AssertionMethod(verificationMethod.Id).
+		Authentication(verificationMethod.Id).
+		Build()
+	require.NoError(t, err)
+	return doc
+}
+
+// roundTrip returns the document as it is received by the verifier
+func roundTrip(t *testing.T, doc *diddoc.Document) *diddoc.Document {
+	data, err := json.Marshal(doc)
+	require.NoError(t, err)
+	received := diddoc.NewDocument()
+	require.NoError(t, json.Unmarshal(data, received))
+	return received
+}
+
+func TestSignAndVerifyProof(t *testing.T) {
+	edPublicKey, edPrivateKey, _ := ed25519.GenerateKey(rand.Reader)
+	edMultibase, _ := multikey.EncodePublicKey(edPublicKey)
+	p256Key, _ := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	p384Key, _ := ecdsa.GenerateKey(elliptic.P384(), rand.Reader)
+	p384JWK, _ := jwk.FromRaw(p384Key.Public())
+	p256Multibase, _ := multikey.EncodePublicKey(p256Key.Public())
+
+	type errorTestCases struct {
+		description        string
+		signer             crypto.Signer
+		cryptosuite        string
+		verificationMethod diddoc.VerificationMethod
+	}
+	for _, scenario := range []errorTestCases{
+		{description: "eddsa-jcs-2022", signer: edPrivateKey, cryptosuite: diddoc.EdDSAJCS2022,
+			verificationMethod: diddoc.VerificationMethod{Id: "did:example:123#key-1", Type: diddoc.MultikeyType, Controller: "did:example:123", PublicKeyMultibase: edMultibase}},
+		{description: "eddsa-rdfc-2022", signer: edPrivateKey, cryptosuite: diddoc.EdDSARDFC2022,
+			verificationMethod: diddoc.VerificationMethod{Id: "did:example:123#key-1", Type: diddoc.MultikeyType, Controller: "did:example:123", PublicKeyMultibase: edMultibase}},
+		{description: "ecdsa-rdfc-2019 p-256", signer: p256Key, cryptosuite: diddoc.ECDSARDFC2019,
+			verificationMethod: diddoc.VerificationMethod{Id: "did:example:123#key-1", Type: diddoc.MultikeyType, Controller: "did:example:123", PublicKeyMultibase: p256Multibase}},
+		{description: "ecdsa-rdfc-2019 p-384 jwk", signer: p384Key, cryptosuite: diddoc.ECDSARDFC2019,
+			verificationMethod: diddoc.VerificationMethod{Id: "did:example:123#key-1", Type: diddoc.JsonWebKey2020Type, Controller: "did:example:123", PubicKeyJWK: p384JWK}},
+	} {
+		t.Run(scenario.description, func(t *testing.T) {
+			doc := signingDocument(t, scenario.verificationMethod)
+			require.NoError(t, doc.Sign(scenario.signer, diddoc.ProofOptions{
+				Cryptosuite:        scenario.cryptosuite,
+				VerificationMethod: "did:example:123#key-1",
+				Created:            time.Date(2023, 2, 24, 23, 36, 38, 0, time.UTC),
+				DocumentLoader:     testDocumentLoader{},
+			}))
+			received := roundTrip(t, doc)
+			assert.NoError(t, received.VerifyProof(nil, diddoc.WithDocumentLoader(testDocumentLoader{})))
+
+			proofs, err := received.Proofs()
+			require.NoError(t, err)
+			require.Len(t, proofs, 1)
+			assert.Equal(t, diddoc.DataIntegrityProofType, proofs[0].Type)
+			assert.Equal(t, scenario.cryptosuite, proofs[0].Cryptosuite)
+			assert.Equal(t, diddoc.AssertionMethod, proofs[0].ProofPurpose)
+			assert.Equal(t, "2023-02-24T23:36:38Z", proofs[0].Created.Format(time.RFC3339))
+		})
+	}
+}
+
+func TestVerifyProofErrors(t *testing.T) {
+	publicKey, privateKey, _ := ed25519.GenerateKey(rand.Reader)
+	multibase, _ := multikey.EncodePublicKey(publicKey)
+	verificationMethod := diddoc.VerificationMethod{Id: "did:example:123#key-1", Type: diddoc.MultikeyType, Controller: "did:example:123", PublicKeyMultibase: multibase}
+
+	payload := []byte(`{"@context":["https://www.w3.org/ns/did/v1"],"id":"did:example:456"}`)
+	signed := func(t *testing.T, opts diddoc.ProofOptions) *diddoc.Document {
+		doc := signingDocument(t, verificationMethod)
+		opts.Cryptosuite = diddoc.EdDSAJCS2022
+		if opts.VerificationMethod == "" {
+			opts.VerificationMethod = verificationMethod.Id
+		}
+		require.NoError(t, doc.Sign(privateKey, opts))
+		return roundTrip(t, doc)
+	}
+
+	t.Run("tampered document", func(t *testing.T) {
+		data, err := json.Marshal(signed(t, diddoc.ProofOptions{}))
+		require.NoError(t, err)
+		var properties map[string]interface{}
+		require.NoError(t, json.Unmarshal(data, &properties))
+		properties["alsoKnownAs"] = []string{"https://example.com/attacker"}
+		data, _ = json.Marshal(properties)
+		tampered := diddoc.NewDocument()
+		require.NoError(t, json.Unmarshal(data, tampered))
+		assert.ErrorContains(t, tampered.VerifyProof(nil), "invalid_signature")
+	})
+	t.Run("proof value not base58btc", func(t *testing.T) {
+		proof, err := diddoc.CreateDataIntegrityProof(payload, privateKey, diddoc.ProofOptions{Cryptosuite: diddoc.EdDSAJCS2022, VerificationMethod: verificationMethod.Id})
+		require.NoError(t, err)
+		signature, err := multikey.DecodeMultibase(proof.ProofValue)
+		require.NoError(t, err)
+		proof.ProofValue, err = multikey.EncodeMultibaseWith(multikey.Base64URL, signature)
+		require.NoError(t, err)
+		assert.ErrorContains(t, diddoc.VerifyDataIntegrityProof(payload, proof, signingDocument(t, verificationMethod), nil), "invalid_proof")
+	})
+	t.Run("purpose not authorized", func(t *testing.T) {
+		doc := signed(t, diddoc.ProofOptions{ProofPurpose: diddoc.CapabilityDelegation})
+		assert.ErrorContains(t, doc.VerifyProof(nil), "not_authorized")
+	})
+	t.Run("relative verification method", func(t *testing.T) {
+		doc := signed(t, diddoc.ProofOptions{VerificationMethod: "#key-1", ProofPurpose: diddoc.Authentication})
+		assert.NoError(t, doc.VerifyProof(nil))
+	})
+	t.Run("domain and challenge", func(t *testing.T) {
+		doc := signed(t, diddoc.ProofOptions{Domain: "example.com", Challenge: "1234"})
+		assert.NoError(t, doc.VerifyProof(nil, diddoc.WithDomain("example.com"), diddoc.WithChallenge("1234")))
+		assert.ErrorContains(t, doc.VerifyProof(nil, diddoc.WithDomain("example.org")), "domain_mismatch")
+		assert.ErrorContains(t, doc.VerifyProof(nil, diddoc.WithChallenge("5678")), "challenge_mismatch")
+	})
+	t.Run("proof set", func(t *testing.T) {
+		doc := signingDocument(t, verificationMethod)
+		for _, purpose := range []diddoc.ProofPurpose{diddoc.AssertionMethod, diddoc.Authentication} {
+			require.NoError(t, doc.Sign(privateKey, diddoc.ProofOptions{Cryptosuite: diddoc.EdDSAJCS2022, VerificationMethod: verificationMethod.Id, ProofPurpose: purpose}))
+		}
+		received := roundTrip(t, doc)
+		proofs, err := received.Proofs()
+		require.NoError(t, err)
+		assert.Len(t, proofs, 2)
+		assert.NoError(t, received.VerifyProof(nil))
+	})
+	t.Run("no proof", func(t *testing.T) {
+		assert.ErrorContains(t, signingDocument(t, verificationMethod).VerifyProof(nil), "proof_not_found")
+	})
+	t.Run("key type mismatch", func(t *testing.T) {
+		doc := signingDocument(t, verificationMethod)
+		p256Key, _ := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+		assert.ErrorContains(t, doc.Sign(p256Key, diddoc.ProofOptions{Cryptosuite: diddoc.EdDSAJCS2022, VerificationMethod: verificationMethod.Id}), "key_type_mismatch")
+	})
+	t.Run("unsupported cryptosuite", func(t *testing.T) {
+		doc := signingDocument(t, verificationMethod)
+		assert.ErrorContains(t, doc.Sign(privateKey, diddoc.ProofOptions{Cryptosuite: "bbs-2023", VerificationMethod: verificationMethod.Id}), "unsupported_cryptosuite")
+	})
+}
+
+func TestVerifyProofOfController(t *testing.T) {
+	publicKey, privateKey, _ := ed25519.GenerateKey(rand.Reader)
+	multibase, _ := multikey.EncodePublicKey(publicKey)
+	controller := signingDocument(t, diddoc.VerificationMethod{Id: "did:example:123#key-1", Type: diddoc.MultikeyType, Controller: "did:example:123", PublicKeyMultibase: multibase})
+
+	doc, err := diddoc.NewBuilder().Context(diddoc.ContextDIDv1).Subject("did:example:456").Controller("did:example:123").Build()
+	require.NoError(t, err)
+	require.NoError(t, doc.Sign(privateKey, diddoc.ProofOptions{Cryptosuite: diddoc.EdDSAJCS2022, VerificationMethod: "did:example:123#key-1"}))
+
+	resolver := diddoc.ResolverFunc(func(ctx context.Context, did string, opts diddoc.ResolutionOptions) (*diddoc.Document, diddoc.ResolutionMetadata, diddoc.DocumentMetadata, error) {
+		if did != "did:example:123" {
+			return nil, diddoc.ResolutionMetadata{Error: diddoc.NotFound}, diddoc.DocumentMetadata{}, diddoc.NotFound
+		}
+		return controller, diddoc.ResolutionMetadata{}, diddoc.DocumentMetadata{}, nil
+	})
+	assert.NoError(t, doc.VerifyProof(resolver))
+	assert.ErrorContains(t, doc.VerifyProof(nil), "controller_not_resolved")
+}
+
+func TestVerifyProofOfUnauthorizedDID(t *testing.T) {
+	// the attacker signs the document of the victim with the key of a did:key of its own, which resolves
+	// and lists the key under its own assertionMethod
+	attackerPublicKey, attackerPrivateKey, _ := ed25519.GenerateKey(rand.Reader)
+	attacker, err := didkey.New(attackerPublicKey)
+	require.NoError(t, err)
+	attackerKey := attacker.String() + "#" + attacker.ID
+
+	victim, err := diddoc.NewBuilder().Context(diddoc.ContextDIDv1).Subject("did:example:victim").Build()
+	require.NoError(t, err)
+	require.NoError(t, victim.Sign(attackerPrivateKey, diddoc.ProofOptions{Cryptosuite: diddoc.EdDSAJCS2022, VerificationMethod: attackerKey}))
+	received := roundTrip(t, victim)
+
+	assert.ErrorContains(t, received.VerifyProof(didkey.NewResolver()), "not_authorized")
+	assert.ErrorContains(t, received.VerifyProof(nil), "not_authorized")
+	assert.False(t, received.IsAuthorized(attackerKey, diddoc.AssertionMethod, diddoc.WithResolver(didkey.NewResolver())))
+
+	t.Run("referenced by the document", func(t *testing.T) {
+		doc, err := diddoc.NewBuilder().Context(diddoc.ContextDIDv1).Subject("did:example:victim").AssertionMethod(attackerKey).Build()
+		require.NoError(t, err)
+		require.NoError(t, doc.Sign(attackerPrivateKey, diddoc.ProofOptions{Cryptosuite: diddoc.EdDSAJCS2022, VerificationMethod: attackerKey}))
+		received := roundTrip(t, doc)
+		assert.NoError(t, received.VerifyProof(didkey.NewResolver()))
+		assert.ErrorContains(t, received.VerifyProof(nil), "controller_not_resolved")
+	})
+}
+
+// credentialsLoader serves the terms of the VC v2 contexts that are used by the credential of the test vectors,
+// and the bundled contexts
+type credentialsLoader struct{}
+
+func (credentialsLoader) LoadDocument(u string) (*ld.RemoteDocument, error) {
+	var context string
+	switch u {
+	case "https://www.w3.org/ns/credentials/v2":
+		context = `{"@context":{"@protected":true,"@vocab":"https://www.w3.org/ns/credentials/issuer-dependent#","id":"@id","type":"@type",
+			"VerifiableCredential":{"@id":"https://www.w3.org/2018/credentials#VerifiableCredential"},
+			"name":{"@id":"https://schema.org/name"},
+			"description":{"@id":"https://schema.org/description"},
+			"issuer":{"@id":"https://www.w3.org/2018/credentials#issuer","@type":"@id"},
+			"validFrom":{"@id":"https://www.w3.org/2018/credentials#validFrom","@type":"http://www.w3.org/2001/XMLSchema#dateTime"},
+			"credentialSubject":{"@id":"https://www.w3.org/2018/credentials#credentialSubject","@type":"@id"},
+			"proof":{"@id":"https://w3id.org/security#proof","@type":"@id","@container":"@graph"},
+			"DataIntegrityProof":{"@id":"https://w3id.org/security#DataIntegrityProof","@context":{"@protected":true,"id":"@id","type":"@type",
+				"created":{"@id":"http://purl.org/dc/terms/created","@type":"http://www.w3.org/2001/XMLSchema#dateTime"},
+				"proofPurpose":{"@id":"https://w3id.org/security#proofPurpose","@type":"@vocab","@context":{"@protected":true,"id":"@id","type":"@type",
+					"assertionMethod":{"@id":"https://w3id.org/security#assertionMethod","@type":"@id","@container":"@set"}}},
+				"cryptosuite":{"@id":"https://w3id.org/security#cryptosuite","@type":"https://w3id.org/security#cryptosuiteString"},
+				"proofValue":{"@id":"https://w3id.org/security#proofValue","@type":"https://w3id.org/security#multibase"},
+				"verificationMethod":{"@id":"https://w3id.org/security#verificationMethod","@type":"@id"}}}}}`
+	case "https://www.w3.org/ns/credentials/examples/v2":
+		context = `{"@context":{"@vocab":"https://www.w3.org/ns/credentials/examples#"}}`
+	default:
+		return diddoc.StaticDocumentLoader(nil).LoadDocument(u)
+	}
+	var document interface{}
+	if err := json.Unmarshal([]byte(context), &document); err != nil {
+		return nil, err
+	}
+	return &ld.RemoteDocument{DocumentURL: u, Document: document}, nil
+}
+
+// TestDataIntegrityTestVectors signs the credential of the test vectors of the W3C Data Integrity EdDSA
+// Cryptosuites (vc-di-eddsa), Ed25519 signatures are deterministic so the proof values must be equal
+func TestDataIntegrityTestVectors(t *testing.T) {
+	_, seed, err := multikey.Decode("z3u2en7t5LR2WtQH5PfFqMqwVHBeXouLzo6haApm8XHqvjxq")
+	require.NoError(t, err)
+	privateKey := ed25519.NewKeyFromSeed(seed)
+	publicKeyMultibase := "z6MkrJVnaZkeFzdQyMZu1cgjg7k1pZZ6pvBQ7XJPt4swbTQ2"
+	controller, err := didkey.Expand("did:key:" + publicKeyMultibase)
+	require.NoError(t, err)
+	credential := []byte(`{"@context":["https://www.w3.org/ns/credentials/v2","https://www.w3.org/ns/credentials/examples/v2"],"id":"urn:uuid:58172aac-d8ba-11ed-83dd-0b3aef56cc33","type":["VerifiableCredential","AlumniCredential"],"name":"Alumni Credential","description":"A minimum viable example of an Alumni Credential.","issuer":"https://vc.example/issuers/5678","validFrom":"2023-01-01T00:00:00Z","credentialSubject":{"id":"did:example:abcdefgh","alumniOf":"The School of Examples"}}`)
+
+	type errorTestCases struct {
+		description        string
+		cryptosuite        string
+		expectedProofValue string
+	}
+	for _, scenario := range []errorTestCases{
+		{description: "eddsa-jcs-2022", cryptosuite: diddoc.EdDSAJCS2022, expectedProofValue: "z2HnFSSPPBzR36zdDgK8PbEHeXbR56YF24jwMpt3R1eHXQzJDMWS93FCzpvJpwTWd3GAVFuUfjoJdcnTMuVor51aX"},
+		{description: "eddsa-rdfc-2022", cryptosuite: diddoc.EdDSARDFC2022, expectedProofValue: "z2YwC8z3ap7yx1nZYCg4L3j3ApHsF8kgPdSb5xoS1VR7vPG3F561B52hYnQF9iseabecm3ijx4K1FBTQsCZahKZme"},
+	} {
+		t.Run(scenario.description, func(t *testing.T) {
+			proof, err := diddoc.CreateDataIntegrityProof(credential, privateKey, diddoc.ProofOptions{
+				Cryptosuite:        scenario.cryptosuite,
+				VerificationMethod: "did:key:" + publicKeyMultibase + "#" + publicKeyMultibase,
+				Created:            time.Date(2023, 2, 24, 23, 36, 38, 0, time.UTC),
+				DocumentLoader:     credentialsLoader{},
+			})
+			require.NoError(t, err)
+			assert.Equal(t, scenario.expectedProofValue, proof.ProofValue)
+			assert.NoError(t, diddoc.VerifyDataIntegrityProof(credential, proof, controller, nil, diddoc.WithDocumentLoader(credentialsLoader{})))
+		})
+	}
+}
+
+func TestVerifyProofWithUnmodelledMembers(t *testing.T) {
+	// the proof of another implementation has an expires member and a created timestamp with milliseconds
+	publicKey, privateKey, _ := ed25519.GenerateKey(rand.Reader)
+	did, err := didkey.New(publicKey)
+	require.NoError(t, err)
+	controller, err := didkey.Expand(did.String())
+	require.NoError(t, err)
+	payload := []byte(`{"@context":"https://www.w3.org/ns/did/v1","id":"did:example:123"}`)
+	config := `{"@context":"https://www.w3.org/ns/did/v1","type":"DataIntegrityProof","cryptosuite":"eddsa-jcs-2022",` +
+		`"created":"2023-02-24T23:36:38.000Z","expires":"2033-02-24T23:36:38Z","verificationMethod":"` + did.String() + "#" + did.ID + `",` +
+		`"proofPurpose":"assertionMethod"}`
+
+	canonicalConfig, err := diddoc.Canonicalize([]byte(config))
+	require.NoError(t, err)
+	canonicalPayload, err := diddoc.Canonicalize(payload)
+	require.NoError(t, err)
+	configHash, payloadHash := sha256.Sum256(canonicalConfig), sha256.Sum256(canonicalPayload)
+	signature := ed25519.Sign(privateKey, append(configHash[:], payloadHash[:]...))
+
+	var members map[string]interface{}
+	require.NoError(t, json.Unmarshal([]byte(config), &members))
+	delete(members, "@context")
+	members["proofValue"] = multikey.EncodeMultibase(signature)
+	data, err := json.Marshal(members)
+	require.NoError(t, err)
+	var proof diddoc.Proof
+	require.NoError(t, json.Unmarshal(data, &proof))
+
+	assert.NoError(t, diddoc.VerifyDataIntegrityProof(payload, proof, controller, nil))
+
+	members["expires"] = "2034-02-24T23:36:38Z"
+	data, err = json.Marshal(members)
+	require.NoError(t, err)
+	require.NoError(t, json.Unmarshal(data, &proof))
+	assert.ErrorContains(t, diddoc.VerifyDataIntegrityProof(payload, proof, controller, nil), "invalid_signature")
+}
+
+func TestStaticDocumentLoader(t *testing.T) {
+	publicKey, privateKey, _ := ed25519.GenerateKey(rand.Reader)
+	multibase, _ := multikey.EncodePublicKey(publicKey)
+	doc := signingDocument(t, diddoc.VerificationMethod{Id: "did:example:123#key-1", Type: diddoc.MultikeyType, Controller: "did:example:123", PublicKeyMultibase: multibase})
+
+	// the bundled contexts canonicalize a DID document without network access
+	require.NoError(t, doc.Sign(privateKey, diddoc.ProofOptions{Cryptosuite: diddoc.EdDSARDFC2022, VerificationMethod: "did:example:123#key-1"}))
+	assert.NoError(t, roundTrip(t, doc).VerifyProof(nil))
+
+	_, err := diddoc.StaticDocumentLoader(nil).LoadDocument("https://www.w3.org/ns/credentials/v2")
+	assert.ErrorContains(t, err, "is not bundled")
+
+	t.Run("embedded context", func(t *testing.T) {
+		embedded := map[string]interface{}{"@vocab": "https://example.com/vocab#"}
+		doc, err := diddoc.NewBuilder().Context([]interface{}{diddoc.ContextDIDv1, embedded}).Subject("did:example:123").
+			VerificationMethod(diddoc.VerificationMethod{Id: "did:example:123#key-1", Type: diddoc.MultikeyType, Controller: "did:example:123", PublicKeyMultibase: multibase}).
+			AssertionMethod("did:example:123#key-1").Build()
+		require.NoError(t, err)
+		require.NoError(t, doc.Sign(privateKey, diddoc.ProofOptions{Cryptosuite: diddoc.EdDSARDFC2022, VerificationMethod: "did:example:123#key-1"}))
+		assert.Equal(t, []interface{}{diddoc.ContextDIDv1, embedded, diddoc.ContextDataIntegrityV2}, doc.Context())
+		assert.NoError(t, roundTrip(t, doc).VerifyProof(nil))
+	})
+}
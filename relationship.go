@@ -0,0 +1,287 @@
+// Copyright 2023 The Go SSI Framework Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+package diddoc
+
+import (
+	"context"
+	"fmt"
+	"strings"
+)
+
+// AssociatedVerificationMethod is a verification method that is listed under a verification relationship
+type AssociatedVerificationMethod struct {
+	VerificationMethod VerificationMethod
+	// Relationship is the verification relationship that lists the verification method
+	Relationship ProofPurpose
+	// Embedded reports whether the verification method is embedded in the relationship, rather than referenced
+	Embedded bool
+	// Reference is the DID URL by which the relationship references the verification method, empty when embedded
+	Reference string
+}
+
+type lookupOptions struct {
+	ctx      context.Context
+	resolver Resolver
+}
+
+// LookupOption configures the lookup of the verification methods of a relationship
+type LookupOption func(*lookupOptions)
+
+// WithResolver resolves the references to verification methods of other DIDs, such as the keys of a controller
+func WithResolver(resolver Resolver) LookupOption {
+	return func(o *lookupOptions) {
+		o.resolver = resolver
+	}
+}
+
+// WithLookupContext sets the context of the resolution of other DIDs, context.Background by default
+func WithLookupContext(ctx context.Context) LookupOption {
+	return func(o *lookupOptions) {
+		o.ctx = ctx
+	}
+}
+
+// LookupVerificationMethods gets the verification methods of the verification relationship of the purpose. A reference
+// is looked up in the verification methods of the document, or in those embedded in another relationship. A reference
+// to another DID is only resolved with a resolver. When a reference can not be resolved, the resolved verification
+// methods are returned along with an unresolved_reference error. The verification methods of a controller, which
+// IsAuthorized also authorizes, are not listed.
+func (d *Document) LookupVerificationMethods(purpose ProofPurpose, opts ...LookupOption) ([]AssociatedVerificationMethod, error) {
+	o := lookupOptions{ctx: context.Background()}
+	for _, opt := range opts {
+		opt(&o)
+	}
+	subject := d.Subject()
+
+	var relations []VerificationRelation
+	if err := encode(&relations, d.Get(purpose.String())); err != nil {
+		return nil, err
+	}
+	if len(relations) == 0 {
+		return nil, errNotFound
+	}
+	var associated []AssociatedVerificationMethod
+	var unresolved []string
+	controllers := map[string]*Document{}
+	for _, relation := range relations {
+		reference, ok := relation.(string)
+		if !ok {
+			var verificationMethod VerificationMethod
+			if err := encode(&verificationMethod, relation); err != nil {
+				return nil, err
+			}
+			associated = append(associated, AssociatedVerificationMethod{
+				VerificationMethod: verificationMethod,
+				Relationship:       purpose,
+				Embedded:           true,
+			})
+			continue
+		}
+		verificationMethod, err := d.resolveReference(reference, subject, o, controllers)
+		if err != nil {
+			unresolved = append(unresolved, reference)
+			continue
+		}
+		associated = append(associated, AssociatedVerificationMethod{
+			VerificationMethod: verificationMethod,
+			Relationship:       purpose,
+			Reference:          reference,
+		})
+	}
+	if len(unresolved) > 0 {
+		return associated, fmt.Errorf("%w: %s", errUnresolvedReference, strings.Join(unresolved, ", "))
+	}
+	return associated, nil
+}
+
+// IsAuthorized reports whether the verification method with the id is authorized for the purpose. It must be listed,
+// embedded or referenced, under the verification relationship of the purpose, and a reference must resolve. A
+// verification method of another DID is also authorized when that DID is a controller of the document, and lists the
+// verification method under its own relationship of the purpose. The verification of proofs and JWS applies this rule.
+func (d *Document) IsAuthorized(methodId string, purpose ProofPurpose, opts ...LookupOption) bool {
+	o := lookupOptions{ctx: context.Background()}
+	for _, opt := range opts {
+		opt(&o)
+	}
+	_, err := d.authorize(methodId, purpose, o)
+	return err == nil
+}
+
+// authorize gets the verification method with the id, when it is authorized for the purpose as reported by IsAuthorized
+func (d *Document) authorize(methodId string, purpose ProofPurpose, o lookupOptions) (VerificationMethod, error) {
+	if methodId == "" {
+		return VerificationMethod{}, errMissingMethod
+	}
+	if purpose == "" {
+		return VerificationMethod{}, errMissingPurpose
+	}
+	subject := d.Subject()
+	methodURL, err := ParseDIDURL(methodId)
+	if err != nil {
+		return VerificationMethod{}, err
+	}
+	methodURL = methodURL.ResolveReference(subject)
+
+	if containsPurpose(d.RelationshipsFor(methodURL.String()), purpose) {
+		return d.resolveReference(methodURL.String(), subject, o, map[string]*Document{})
+	}
+	if methodURL.DID.String() == subject.String() || !d.isController(methodURL.DID) {
+		return VerificationMethod{}, fmt.Errorf("%w: %s is not listed under %s", errNotAuthorized, methodId, purpose)
+	}
+	// the method of a controller must be listed under the relationship of the controller document
+	controller, err := resolveController(o.ctx, o.resolver, methodURL.DID.String())
+	if err != nil {
+		return VerificationMethod{}, err
+	}
+	if !containsPurpose(controller.RelationshipsFor(methodURL.String()), purpose) {
+		return VerificationMethod{}, fmt.Errorf("%w: %s is not listed under %s of the controller", errNotAuthorized, methodId, purpose)
+	}
+	unlock := controller.rlock()
+	verificationMethod, err := controller.findVerificationMethod(methodURL.String())
+	unlock()
+	if err != nil {
+		return VerificationMethod{}, err
+	}
+	verificationMethod.Id = methodURL.String()
+	return verificationMethod, nil
+}
+
+// isController reports whether the DID is listed in the controller property of the document
+func (d *Document) isController(did DID) bool {
+	var controllers []string
+	if err := encode(&controllers, d.Get(controllerKey)); err != nil {
+		return false
+	}
+	for _, controller := range controllers {
+		if controller == did.String() {
+			return true
+		}
+	}
+	return false
+}
+
+func containsPurpose(purposes []ProofPurpose, purpose ProofPurpose) bool {
+	for _, p := range purposes {
+		if p == purpose {
+			return true
+		}
+	}
+	return false
+}
+
+// resolveReference gets the referenced verification method, from the document itself or from the resolved
+// document of another DID, the resolved documents are kept in the controllers
+func (d *Document) resolveReference(reference string, subject DID, o lookupOptions, controllers map[string]*Document) (VerificationMethod, error) {
+	u, err := ParseDIDURL(reference)
+	if err != nil {
+		return VerificationMethod{}, err
+	}
+	u = u.ResolveReference(subject)
+	if u.DID.String() == subject.String() {
+		defer d.rlock()()
+		return d.findVerificationMethod(u.String())
+	}
+	controller, ok := controllers[u.DID.String()]
+	if !ok {
+		controller, err = resolveController(o.ctx, o.resolver, u.DID.String())
+		if err != nil {
+			return VerificationMethod{}, err
+		}
+		controllers[u.DID.String()] = controller
+	}
+	unlock := controller.rlock()
+	verificationMethod, err := controller.findVerificationMethod(u.String())
+	unlock()
+	if err != nil {
+		return VerificationMethod{}, err
+	}
+	// the id is made absolute, as a relative id is relative to the other DID
+	verificationMethod.Id = normalizeDIDURL(verificationMethod.Id, u.DID)
+	return verificationMethod, nil
+}
+
+// resolveController resolves the DID document of another DID, a resolver that returns no document does not resolve it
+func resolveController(ctx context.Context, resolver Resolver, did string) (*Document, error) {
+	if resolver == nil {
+		return nil, errControllerNotResolved
+	}
+	controller, _, _, err := resolver.Resolve(ctx, did, ResolutionOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", errControllerNotResolved, err)
+	}
+	if controller == nil {
+		return nil, fmt.Errorf("%w: no document for %s", errControllerNotResolved, did)
+	}
+	return controller, nil
+}
+
+// findVerificationMethod gets the verification method by its id from the verificationMethod property,
+// or from the verification methods that are embedded in a relationship, the document must be locked
+func (d *Document) findVerificationMethod(keyId string) (VerificationMethod, error) {
+	if verificationMethod, err := d.getVerificationMethodById(keyId); err == nil {
+		return verificationMethod, nil
+	}
+	subject := d.subject()
+	keyId = normalizeDIDURL(keyId, subject)
+	for _, key := range verificationRelationshipKeys {
+		var relations []VerificationRelation
+		if err := encode(&relations, d.get(key)); err != nil {
+			continue
+		}
+		for _, relation := range relations {
+			if _, ok := relation.(string); ok {
+				continue
+			}
+			var verificationMethod VerificationMethod
+			if err := encode(&verificationMethod, relation); err != nil {
+				continue
+			}
+			if normalizeDIDURL(verificationMethod.Id, subject) == keyId {
+				return verificationMethod, nil
+			}
+		}
+	}
+	return VerificationMethod{}, errNotFound
+}
+
+// RelationshipsFor returns the verification relationships that list the verification method with the id, embedded or
+// referenced. Relative and absolute ids are equivalent.
+func (d *Document) RelationshipsFor(methodId string) []ProofPurpose {
+	defer d.rlock()()
+
+	subject := d.subject()
+	methodId = normalizeDIDURL(methodId, subject)
+
+	var purposes []ProofPurpose
+	for _, key := range verificationRelationshipKeys {
+		var relations []VerificationRelation
+		if err := encode(&relations, d.get(key)); err != nil {
+			continue
+		}
+		for _, relation := range relations {
+			if relationId(relation, subject) == methodId {
+				purposes = append(purposes, ProofPurpose(key))
+				break
+			}
+		}
+	}
+	return purposes
+}
+
+// relationId returns the absolute id of the verification method of a relationship item, which is a reference
+// or an embedded verification method
+func relationId(relation VerificationRelation, subject DID) string {
+	var id string
+	switch value := relation.(type) {
+	case string:
+		id = value
+	case VerificationMethod:
+		id = value.Id
+	case *VerificationMethod:
+		id = value.Id
+	case map[string]interface{}:
+		id, _ = value[subjectKey].(string)
+	}
+	return normalizeDIDURL(id, subject)
+}
@@ -0,0 +1,117 @@
+// Copyright 2023 The Go SSI Framework Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+package diddoc_test
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/gossif/diddoc"
+	"github.com/gossif/diddoc/didkey"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const relationshipDocument string = `{"@context":"https://www.w3.org/ns/did/v1","id":"did:example:123","controller":"did:key:z6MkhaXgBZDvotDkL5257faiztiGiC2QtKLGpbnnEGta2doK","verificationMethod":[{"id":"#key-1","type":"Multikey","controller":"did:example:123","publicKeyMultibase":"z6MkhaXgBZDvotDkL5257faiztiGiC2QtKLGpbnnEGta2doK"}],"authentication":["#key-1",{"id":"#key-2","type":"Multikey","controller":"did:example:123","publicKeyMultibase":"zDnaerDaTF5BXEavCrfRZEk316dpbLsfPDZ3WJ5hRTPFU2169"},"did:key:z6MkhaXgBZDvotDkL5257faiztiGiC2QtKLGpbnnEGta2doK#z6MkhaXgBZDvotDkL5257faiztiGiC2QtKLGpbnnEGta2doK"],"assertionMethod":["#key-2","#key-3"]}`
+
+func TestLookupVerificationMethods(t *testing.T) {
+	doc := diddoc.NewDocument()
+	require.NoError(t, json.Unmarshal([]byte(relationshipDocument), doc))
+
+	t.Run("with resolver", func(t *testing.T) {
+		associated, err := doc.LookupVerificationMethods(diddoc.Authentication, diddoc.WithResolver(didkey.NewResolver()))
+		require.NoError(t, err)
+		require.Len(t, associated, 3)
+
+		assert.Equal(t, "#key-1", associated[0].VerificationMethod.Id)
+		assert.Equal(t, "#key-1", associated[0].Reference)
+		assert.False(t, associated[0].Embedded)
+		assert.Equal(t, diddoc.Authentication, associated[0].Relationship)
+
+		assert.Equal(t, "#key-2", associated[1].VerificationMethod.Id)
+		assert.True(t, associated[1].Embedded)
+
+		assert.Equal(t, "did:key:z6MkhaXgBZDvotDkL5257faiztiGiC2QtKLGpbnnEGta2doK#z6MkhaXgBZDvotDkL5257faiztiGiC2QtKLGpbnnEGta2doK", associated[2].VerificationMethod.Id)
+		assert.False(t, associated[2].Embedded)
+	})
+	t.Run("without resolver", func(t *testing.T) {
+		associated, err := doc.LookupVerificationMethods(diddoc.Authentication)
+		assert.ErrorContains(t, err, "unresolved_reference: did:key:z6MkhaXgBZDvotDkL5257faiztiGiC2QtKLGpbnnEGta2doK#z6MkhaXgBZDvotDkL5257faiztiGiC2QtKLGpbnnEGta2doK")
+		assert.Len(t, associated, 2)
+	})
+	t.Run("reference to embedded method", func(t *testing.T) {
+		associated, err := doc.LookupVerificationMethods(diddoc.AssertionMethod)
+		assert.ErrorContains(t, err, "unresolved_reference: #key-3")
+		require.Len(t, associated, 1)
+		assert.Equal(t, "#key-2", associated[0].VerificationMethod.Id)
+	})
+	t.Run("no relationship", func(t *testing.T) {
+		_, err := doc.LookupVerificationMethods(diddoc.KeyAgreement)
+		assert.ErrorContains(t, err, "not_found")
+	})
+}
+
+func TestIsAuthorized(t *testing.T) {
+	doc := diddoc.NewDocument()
+	require.NoError(t, json.Unmarshal([]byte(relationshipDocument), doc))
+	controllerKey := "did:key:z6MkhaXgBZDvotDkL5257faiztiGiC2QtKLGpbnnEGta2doK#z6MkhaXgBZDvotDkL5257faiztiGiC2QtKLGpbnnEGta2doK"
+	resolver := diddoc.WithResolver(didkey.NewResolver())
+
+	assert.True(t, doc.IsAuthorized("did:example:123#key-1", diddoc.Authentication))
+	assert.True(t, doc.IsAuthorized("#key-2", diddoc.Authentication))
+	assert.False(t, doc.IsAuthorized("#key-1", diddoc.AssertionMethod))
+	assert.False(t, doc.IsAuthorized("#key-3", diddoc.AssertionMethod))
+	assert.False(t, doc.IsAuthorized(controllerKey, diddoc.Authentication))
+	assert.True(t, doc.IsAuthorized(controllerKey, diddoc.Authentication, resolver, diddoc.WithLookupContext(context.Background())))
+	// the key of the controller is authorized by the controller document, for its own relationships only
+	assert.True(t, doc.IsAuthorized(controllerKey, diddoc.AssertionMethod, resolver))
+	assert.False(t, doc.IsAuthorized(controllerKey, diddoc.KeyAgreement, resolver))
+	assert.False(t, doc.IsAuthorized("did:key:z6MkrJVnaZkeFzdQyMZu1cgjg7k1pZZ6pvBQ7XJPt4swbTQ2#z6MkrJVnaZkeFzdQyMZu1cgjg7k1pZZ6pvBQ7XJPt4swbTQ2", diddoc.AssertionMethod, resolver))
+}
+
+func TestRelationshipsFor(t *testing.T) {
+	doc := diddoc.NewDocument()
+	require.NoError(t, json.Unmarshal([]byte(relationshipDocument), doc))
+
+	type errorTestCases struct {
+		description    string
+		input          string
+		expectedOutput []diddoc.ProofPurpose
+	}
+	for _, scenario := range []errorTestCases{
+		{description: "relative reference", input: "#key-1", expectedOutput: []diddoc.ProofPurpose{diddoc.Authentication}},
+		{description: "absolute reference", input: "did:example:123#key-1", expectedOutput: []diddoc.ProofPurpose{diddoc.Authentication}},
+		{description: "embedded and referenced", input: "did:example:123#key-2", expectedOutput: []diddoc.ProofPurpose{diddoc.Authentication, diddoc.AssertionMethod}},
+		{description: "other did", input: "did:key:z6MkhaXgBZDvotDkL5257faiztiGiC2QtKLGpbnnEGta2doK#z6MkhaXgBZDvotDkL5257faiztiGiC2QtKLGpbnnEGta2doK", expectedOutput: []diddoc.ProofPurpose{diddoc.Authentication}},
+		{description: "not listed", input: "#key-4", expectedOutput: nil},
+		{description: "version parameter", input: "did:example:123?versionId=1#key-1", expectedOutput: []diddoc.ProofPurpose{diddoc.Authentication}},
+		{description: "other parameter", input: "did:example:123?service=a#key-1", expectedOutput: nil},
+	} {
+		t.Run(scenario.description, func(t *testing.T) {
+			assert.Equal(t, scenario.expectedOutput, doc.RelationshipsFor(scenario.input))
+		})
+	}
+}
+
+func TestResolverWithoutDocument(t *testing.T) {
+	doc := diddoc.NewDocument()
+	require.NoError(t, json.Unmarshal([]byte(relationshipDocument), doc))
+	controllerKey := "did:key:z6MkhaXgBZDvotDkL5257faiztiGiC2QtKLGpbnnEGta2doK#z6MkhaXgBZDvotDkL5257faiztiGiC2QtKLGpbnnEGta2doK"
+	resolver := diddoc.ResolverFunc(func(ctx context.Context, did string, opts diddoc.ResolutionOptions) (*diddoc.Document, diddoc.ResolutionMetadata, diddoc.DocumentMetadata, error) {
+		return nil, diddoc.ResolutionMetadata{}, diddoc.DocumentMetadata{}, nil
+	})
+
+	assert.False(t, doc.IsAuthorized(controllerKey, diddoc.Authentication, diddoc.WithResolver(resolver)))
+	assert.False(t, doc.IsAuthorized(controllerKey, diddoc.AssertionMethod, diddoc.WithResolver(resolver)))
+	_, err := doc.LookupVerificationMethods(diddoc.Authentication, diddoc.WithResolver(resolver))
+	assert.ErrorContains(t, err, "unresolved_reference")
+	var pointers []string
+	for _, v := range doc.Validate(diddoc.ValidationOptions{Resolver: resolver}).Errors() {
+		pointers = append(pointers, v.Pointer)
+	}
+	assert.Contains(t, pointers, "/authentication/2")
+	_, err = diddoc.VerifyJWS(context.Background(), resolver, []byte("eyJhbGciOiJFZERTQSIsImtpZCI6ImRpZDprZXk6ejZNa2hhWGdCWkR2b3REa0w1MjU3ZmFpenRpR2lDMlF0S0xHcGJubkVHdGEyZG9LI3o2TWtoYVhnQlpEdm90RGtMNTI1N2ZhaXp0aUdpQzJRdEtMR3Bibm5FR3RhMmRvSyJ9.e30.c2ln"), diddoc.Authentication)
+	assert.ErrorContains(t, err, "controller_not_resolved")
+}
@@ -0,0 +1,155 @@
+// Copyright 2023 The Go SSI Framework Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+package diddoc
+
+import (
+	"context"
+	"errors"
+	"sync"
+)
+
+const (
+	// ContentTypeDIDJSON is the media type of the JSON representation of a DID document
+	ContentTypeDIDJSON string = "application/did+json"
+	// ContentTypeDIDLDJSON is the media type of the JSON-LD representation of a DID document
+	ContentTypeDIDLDJSON string = "application/did+ld+json"
+)
+
+// ResolutionError is an error code as defined in the DID Resolution specification.
+type ResolutionError string
+
+const (
+	InvalidDid                 ResolutionError = "invalidDid"
+	NotFound                   ResolutionError = "notFound"
+	MethodNotSupported         ResolutionError = "methodNotSupported"
+	RepresentationNotSupported ResolutionError = "representationNotSupported"
+	InvalidDidDocument         ResolutionError = "invalidDidDocument"
+	InternalError              ResolutionError = "internalError"
+)
+
+func (e ResolutionError) Error() string {
+	return string(e)
+}
+
+// ResolutionOptions are the input options of the resolve function.
+type ResolutionOptions struct {
+	// Accept is the media type of the preferred representation of the DID document
+	Accept string `json:"accept,omitempty"`
+	// VersionId requests a specific version of the DID document
+	VersionId string `json:"versionId,omitempty"`
+	// VersionTime requests the version of the DID document that was valid at the given time
+	VersionTime string `json:"versionTime,omitempty"`
+}
+
+// ResolutionMetadata is the metadata about the result of the resolve function.
+type ResolutionMetadata struct {
+	// ContentType is the media type of the returned representation
+	ContentType string `json:"contentType,omitempty"`
+	// Error is the error code of the resolution process
+	Error ResolutionError `json:"error,omitempty"`
+}
+
+// Resolver resolves a DID into a DID document.
+type Resolver interface {
+	Resolve(ctx context.Context, did string, opts ResolutionOptions) (*Document, ResolutionMetadata, DocumentMetadata, error)
+}
+
+// ResolverFunc is an adapter to allow the use of ordinary functions as resolvers.
+type ResolverFunc func(ctx context.Context, did string, opts ResolutionOptions) (*Document, ResolutionMetadata, DocumentMetadata, error)
+
+// Resolve calls f(ctx, did, opts)
+func (f ResolverFunc) Resolve(ctx context.Context, did string, opts ResolutionOptions) (*Document, ResolutionMetadata, DocumentMetadata, error) {
+	return f(ctx, did, opts)
+}
+
+// Registry is a resolver that dispatches on the DID method name to the registered resolvers.
+type Registry struct {
+	mu        *sync.RWMutex
+	resolvers map[string]Resolver
+}
+
+// NewRegistry creates a registry instance
+func NewRegistry() *Registry {
+	return &Registry{
+		mu:        &sync.RWMutex{},
+		resolvers: map[string]Resolver{},
+	}
+}
+
+// Register registers the resolver for a DID method, replacing any previously registered resolver
+func (r *Registry) Register(method string, resolver Resolver) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.resolvers[method] = resolver
+}
+
+// Methods returns the DID methods that are supported by the registry
+func (r *Registry) Methods() []string {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	methods := make([]string, 0, len(r.resolvers))
+	for method := range r.resolvers {
+		methods = append(methods, method)
+	}
+	return methods
+}
+
+// Resolve resolves the DID with the resolver that is registered for its method. The document metadata is attached
+// to a copy of the resolved document, which is a snapshot when the resolver returns a snapshot.
+func (r *Registry) Resolve(ctx context.Context, did string, opts ResolutionOptions) (*Document, ResolutionMetadata, DocumentMetadata, error) {
+	parsed, err := ParseDID(did)
+	if err != nil {
+		return resolutionFailed(InvalidDid)
+	}
+	if !isSupportedRepresentation(opts.Accept) {
+		return resolutionFailed(RepresentationNotSupported)
+	}
+	r.mu.RLock()
+	resolver, ok := r.resolvers[parsed.Method]
+	r.mu.RUnlock()
+	if !ok {
+		return resolutionFailed(MethodNotSupported)
+	}
+	doc, resolutionMetadata, documentMetadata, err := resolver.Resolve(ctx, did, opts)
+	if err != nil {
+		var code ResolutionError
+		if !errors.As(err, &code) {
+			code = InternalError
+		}
+		resolutionMetadata.Error = code
+		return nil, resolutionMetadata, documentMetadata, err
+	}
+	if doc != nil {
+		// the metadata is attached to a copy, as the resolver may cache or share the document
+		readOnly := doc.ReadOnly()
+		doc = doc.Clone()
+		if err := doc.SetMetadata(documentMetadata); err != nil {
+			return resolutionFailed(InternalError)
+		}
+		if readOnly {
+			doc = doc.Snapshot()
+		}
+	}
+	if resolutionMetadata.ContentType == "" {
+		resolutionMetadata.ContentType = ContentTypeDIDLDJSON
+		if opts.Accept != "" {
+			resolutionMetadata.ContentType = opts.Accept
+		}
+	}
+	return doc, resolutionMetadata, documentMetadata, nil
+}
+
+func resolutionFailed(code ResolutionError) (*Document, ResolutionMetadata, DocumentMetadata, error) {
+	return nil, ResolutionMetadata{Error: code}, DocumentMetadata{}, code
+}
+
+func isSupportedRepresentation(accept string) bool {
+	switch accept {
+	case "", ContentTypeDIDJSON, ContentTypeDIDLDJSON:
+		return true
+	}
+	return false
+}
@@ -0,0 +1,83 @@
+// Copyright 2023 The Go SSI Framework Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+package diddoc_test
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/gossif/diddoc"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func exampleResolver(ctx context.Context, did string, opts diddoc.ResolutionOptions) (*diddoc.Document, diddoc.ResolutionMetadata, diddoc.DocumentMetadata, error) {
+	if did != "did:example:123" {
+		return nil, diddoc.ResolutionMetadata{}, diddoc.DocumentMetadata{}, fmt.Errorf("%w: %s", diddoc.NotFound, did)
+	}
+	doc, err := diddoc.NewBuilder().Context("https://www.w3.org/ns/did/v1").Subject(did).Build()
+	if err != nil {
+		return nil, diddoc.ResolutionMetadata{}, diddoc.DocumentMetadata{}, err
+	}
+	return doc, diddoc.ResolutionMetadata{}, diddoc.DocumentMetadata{VersionId: "1"}, nil
+}
+
+func TestRegistryResolve(t *testing.T) {
+	registry := diddoc.NewRegistry()
+	registry.Register("example", diddoc.ResolverFunc(exampleResolver))
+
+	type errorTestCases struct {
+		description         string
+		input               string
+		accept              string
+		expectedContentType string
+		expectedError       diddoc.ResolutionError
+	}
+	for _, scenario := range []errorTestCases{
+		{description: "found", input: "did:example:123", expectedContentType: diddoc.ContentTypeDIDLDJSON},
+		{description: "found json", input: "did:example:123", accept: diddoc.ContentTypeDIDJSON, expectedContentType: diddoc.ContentTypeDIDJSON},
+		{description: "not found", input: "did:example:456", expectedError: diddoc.NotFound},
+		{description: "invalid did", input: "did:example", expectedError: diddoc.InvalidDid},
+		{description: "method not supported", input: "did:unknown:123", expectedError: diddoc.MethodNotSupported},
+		{description: "representation not supported", input: "did:example:123", accept: "application/did+cbor", expectedError: diddoc.RepresentationNotSupported},
+	} {
+		t.Run(scenario.description, func(t *testing.T) {
+
+			doc, resolutionMetadata, _, err := registry.Resolve(context.Background(), scenario.input, diddoc.ResolutionOptions{Accept: scenario.accept})
+			if scenario.expectedError != "" {
+				assert.ErrorIs(t, err, scenario.expectedError)
+				assert.Equal(t, scenario.expectedError, resolutionMetadata.Error)
+				assert.Nil(t, doc)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, scenario.expectedContentType, resolutionMetadata.ContentType)
+			assert.Equal(t, scenario.input, doc.Subject().String())
+			assert.Equal(t, "1", doc.Metadata().VersionId)
+		})
+	}
+}
+
+func TestRegistryResolveSharedDocument(t *testing.T) {
+	shared, err := diddoc.NewBuilder().Context(diddoc.ContextDIDv1).Subject("did:example:123").Build()
+	require.NoError(t, err)
+	snapshot := shared.Snapshot()
+
+	for description, cached := range map[string]*diddoc.Document{"document": shared, "snapshot": snapshot} {
+		t.Run(description, func(t *testing.T) {
+			registry := diddoc.NewRegistry()
+			registry.Register("example", diddoc.ResolverFunc(func(ctx context.Context, did string, opts diddoc.ResolutionOptions) (*diddoc.Document, diddoc.ResolutionMetadata, diddoc.DocumentMetadata, error) {
+				return cached, diddoc.ResolutionMetadata{}, diddoc.DocumentMetadata{VersionId: "1"}, nil
+			}))
+
+			doc, _, _, err := registry.Resolve(context.Background(), "did:example:123", diddoc.ResolutionOptions{})
+			require.NoError(t, err)
+			assert.Equal(t, "1", doc.Metadata().VersionId)
+			assert.Equal(t, cached.ReadOnly(), doc.ReadOnly())
+			// the document of the resolver is left unchanged
+			assert.Empty(t, cached.Metadata().VersionId)
+		})
+	}
+}
@@ -0,0 +1,216 @@
+// Copyright 2023 The Go SSI Framework Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+package diddoc
+
+import (
+	"encoding/json"
+	"errors"
+)
+
+const (
+	DIDCommMessagingType             string = "DIDCommMessaging"
+	LinkedDomainsType                string = "LinkedDomains"
+	LinkedVerifiablePresentationType string = "LinkedVerifiablePresentation"
+
+	uriKey     string = "uri"
+	originsKey string = "origins"
+)
+
+var (
+	errInvalidServiceEndpoint error = errors.New("invalid_service_endpoint")
+	errServiceTypeMismatch    error = errors.New("service_type_mismatch")
+)
+
+// ServiceEndpoint is the serviceEndpoint of a service. The value MUST be a string, a map,
+// or a set composed of one or more strings and/or maps.
+type ServiceEndpoint struct {
+	// value is a string, a map[string]interface{} or a []interface{} of strings and maps
+	value interface{}
+}
+
+// DIDCommEndpoint is the structured service endpoint of a DIDCommMessaging service.
+type DIDCommEndpoint struct {
+	URI         string   `json:"uri"`
+	Accept      []string `json:"accept,omitempty"`
+	RoutingKeys []string `json:"routingKeys,omitempty"`
+}
+
+// NewServiceEndpoint creates a service endpoint from a string, a map, a set of strings and/or maps,
+// or any value that marshals to one of these shapes, such as a DIDCommEndpoint
+func NewServiceEndpoint(v interface{}) (ServiceEndpoint, error) {
+	var e ServiceEndpoint
+	if err := e.decode(v); err != nil {
+		return ServiceEndpoint{}, err
+	}
+	return e, nil
+}
+
+// URIServiceEndpoint creates a service endpoint of a single URI
+func URIServiceEndpoint(uri string) ServiceEndpoint {
+	return ServiceEndpoint{value: uri}
+}
+
+// Value returns the string, map[string]interface{} or []interface{} value of the service endpoint
+func (e ServiceEndpoint) Value() interface{} {
+	return e.value
+}
+
+// IsZero reports whether the service endpoint is empty
+func (e ServiceEndpoint) IsZero() bool {
+	return e.value == nil
+}
+
+// URIs returns the URI strings of the service endpoint, including the uri of the maps
+func (e ServiceEndpoint) URIs() []string {
+	var uris []string
+	for _, item := range e.items() {
+		switch value := item.(type) {
+		case string:
+			uris = append(uris, value)
+		case map[string]interface{}:
+			if uri, ok := value[uriKey].(string); ok {
+				uris = append(uris, uri)
+			}
+		}
+	}
+	return uris
+}
+
+// items returns the service endpoint as a set
+func (e ServiceEndpoint) items() []interface{} {
+	switch value := e.value.(type) {
+	case nil:
+		return nil
+	case []interface{}:
+		return value
+	default:
+		return []interface{}{value}
+	}
+}
+
+func (e ServiceEndpoint) MarshalJSON() ([]byte, error) {
+	return json.Marshal(e.value)
+}
+
+func (e *ServiceEndpoint) UnmarshalJSON(data []byte) error {
+	var value interface{}
+	if err := json.Unmarshal(data, &value); err != nil {
+		return err
+	}
+	return e.decode(value)
+}
+
+// decode implements the decoder of the encoder, it normalizes the source into one of the three shapes
+func (e *ServiceEndpoint) decode(v interface{}) error {
+	switch value := v.(type) {
+	case ServiceEndpoint:
+		e.value = value.value
+		return nil
+	case *ServiceEndpoint:
+		e.value = value.value
+		return nil
+	case string:
+		e.value = value
+		return nil
+	case map[string]interface{}:
+		e.value = value
+		return nil
+	case []interface{}:
+		if len(value) == 0 {
+			return errInvalidServiceEndpoint
+		}
+		for _, item := range value {
+			switch item.(type) {
+			case string, map[string]interface{}:
+			default:
+				return errInvalidServiceEndpoint
+			}
+		}
+		e.value = value
+		return nil
+	case nil, bool, float64, json.Number:
+		return errInvalidServiceEndpoint
+	}
+	// other types, e.g. []string or DIDCommEndpoint, are normalized through their JSON representation
+	data, err := json.Marshal(v)
+	if err != nil {
+		return errInvalidServiceEndpoint
+	}
+	var generic interface{}
+	if err := json.Unmarshal(data, &generic); err != nil {
+		return errInvalidServiceEndpoint
+	}
+	switch generic.(type) {
+	case string, map[string]interface{}, []interface{}:
+		return e.decode(generic)
+	}
+	return errInvalidServiceEndpoint
+}
+
+// DIDCommEndpoints returns the endpoints of a DIDCommMessaging service, a URI string is an endpoint without options
+func (s Service) DIDCommEndpoints() ([]DIDCommEndpoint, error) {
+	if s.Type != DIDCommMessagingType {
+		return nil, errServiceTypeMismatch
+	}
+	var endpoints []DIDCommEndpoint
+	for _, item := range s.ServiceEndpoint.items() {
+		var endpoint DIDCommEndpoint
+		switch value := item.(type) {
+		case string:
+			endpoint.URI = value
+		case map[string]interface{}:
+			data, err := json.Marshal(value)
+			if err != nil {
+				return nil, err
+			}
+			if err := json.Unmarshal(data, &endpoint); err != nil {
+				return nil, errInvalidServiceEndpoint
+			}
+		}
+		if endpoint.URI == "" {
+			return nil, errInvalidServiceEndpoint
+		}
+		endpoints = append(endpoints, endpoint)
+	}
+	if len(endpoints) == 0 {
+		return nil, errInvalidServiceEndpoint
+	}
+	return endpoints, nil
+}
+
+// LinkedDomains returns the origins of a LinkedDomains service, the endpoint is an origin, a set of origins or a map with origins
+func (s Service) LinkedDomains() ([]string, error) {
+	if s.Type != LinkedDomainsType {
+		return nil, errServiceTypeMismatch
+	}
+	var origins []string
+	for _, item := range s.ServiceEndpoint.items() {
+		switch value := item.(type) {
+		case string:
+			origins = append(origins, value)
+		case map[string]interface{}:
+			var d []string
+			if err := encode(&d, value[originsKey]); err != nil {
+				return nil, errInvalidServiceEndpoint
+			}
+			origins = append(origins, d...)
+		}
+	}
+	if len(origins) == 0 {
+		return nil, errInvalidServiceEndpoint
+	}
+	return origins, nil
+}
+
+// LinkedVerifiablePresentations returns the URIs of a LinkedVerifiablePresentation service
+func (s Service) LinkedVerifiablePresentations() ([]string, error) {
+	if s.Type != LinkedVerifiablePresentationType {
+		return nil, errServiceTypeMismatch
+	}
+	uris := s.ServiceEndpoint.URIs()
+	if len(uris) == 0 {
+		return nil, errInvalidServiceEndpoint
+	}
+	return uris, nil
+}
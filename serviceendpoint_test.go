@@ -0,0 +1,102 @@
+// Copyright 2023 The Go SSI Framework Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+package diddoc_test
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/gossif/diddoc"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestServiceEndpointRoundTrip(t *testing.T) {
+	type errorTestCases struct {
+		description  string
+		input        string
+		expectedURIs []string
+	}
+	for _, scenario := range []errorTestCases{
+		{description: "uri", input: `"https://example.com/endpoint"`, expectedURIs: []string{"https://example.com/endpoint"}},
+		{description: "map", input: `{"accept":["didcomm/v2"],"routingKeys":["did:example:mediator#key-1"],"uri":"https://example.com/didcomm"}`, expectedURIs: []string{"https://example.com/didcomm"}},
+		{description: "set", input: `["https://example.com/1",{"uri":"https://example.com/2"}]`, expectedURIs: []string{"https://example.com/1", "https://example.com/2"}},
+	} {
+		t.Run(scenario.description, func(t *testing.T) {
+			inputBytes := []byte(`{"id":"did:example:123","service":[{"id":"did:example:123#service-1","type":"DIDCommMessaging","serviceEndpoint":` + scenario.input + `}]}`)
+
+			doc := diddoc.NewDocument()
+			require.NoError(t, json.Unmarshal(inputBytes, doc))
+
+			services := doc.Services().([]diddoc.Service)
+			assert.Equal(t, scenario.expectedURIs, services[0].ServiceEndpoint.URIs())
+
+			actualBytes, err := json.Marshal(services[0].ServiceEndpoint)
+			assert.NoError(t, err)
+			assert.JSONEq(t, scenario.input, string(actualBytes))
+		})
+	}
+}
+
+func TestNewServiceEndpoint(t *testing.T) {
+	type errorTestCases struct {
+		description   string
+		input         interface{}
+		expectedValue interface{}
+		expectedError string
+	}
+	for _, scenario := range []errorTestCases{
+		{description: "string", input: "https://example.com", expectedValue: "https://example.com"},
+		{description: "string set", input: []string{"https://example.com/1", "https://example.com/2"}, expectedValue: []interface{}{"https://example.com/1", "https://example.com/2"}},
+		{description: "didcomm", input: diddoc.DIDCommEndpoint{URI: "https://example.com"}, expectedValue: map[string]interface{}{"uri": "https://example.com"}},
+		{description: "number", input: 42.0, expectedError: "invalid_service_endpoint"},
+		{description: "empty set", input: []interface{}{}, expectedError: "invalid_service_endpoint"},
+		{description: "nested set", input: []interface{}{[]interface{}{"https://example.com"}}, expectedError: "invalid_service_endpoint"},
+	} {
+		t.Run(scenario.description, func(t *testing.T) {
+
+			endpoint, err := diddoc.NewServiceEndpoint(scenario.input)
+			if scenario.expectedError != "" {
+				assert.ErrorContains(t, err, scenario.expectedError)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, scenario.expectedValue, endpoint.Value())
+		})
+	}
+}
+
+func TestServiceAccessors(t *testing.T) {
+	inputBytes := []byte(`{"id":"did:example:123","service":[` +
+		`{"id":"#didcomm","type":"DIDCommMessaging","serviceEndpoint":[{"uri":"https://example.com/didcomm","accept":["didcomm/v2"],"routingKeys":["did:example:mediator#key-1"]},"ws://example.com/didcomm"]},` +
+		`{"id":"#domains","type":"LinkedDomains","serviceEndpoint":{"origins":["https://foo.example.com","https://identity.foundation"]}},` +
+		`{"id":"#domain","type":"LinkedDomains","serviceEndpoint":"https://bar.example.com"},` +
+		`{"id":"#vp","type":"LinkedVerifiablePresentation","serviceEndpoint":["https://bar.example.com/verifiable-presentation.jsonld","https://bar.example.com/verifiable-presentation.jwt"]}]}`)
+
+	doc := diddoc.NewDocument()
+	require.NoError(t, json.Unmarshal(inputBytes, doc))
+	services := doc.Services().([]diddoc.Service)
+
+	endpoints, err := services[0].DIDCommEndpoints()
+	assert.NoError(t, err)
+	assert.Equal(t, []diddoc.DIDCommEndpoint{
+		{URI: "https://example.com/didcomm", Accept: []string{"didcomm/v2"}, RoutingKeys: []string{"did:example:mediator#key-1"}},
+		{URI: "ws://example.com/didcomm"},
+	}, endpoints)
+
+	origins, err := services[1].LinkedDomains()
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"https://foo.example.com", "https://identity.foundation"}, origins)
+
+	origins, err = services[2].LinkedDomains()
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"https://bar.example.com"}, origins)
+
+	presentations, err := services[3].LinkedVerifiablePresentations()
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"https://bar.example.com/verifiable-presentation.jsonld", "https://bar.example.com/verifiable-presentation.jwt"}, presentations)
+
+	_, err = services[3].DIDCommEndpoints()
+	assert.ErrorContains(t, err, "service_type_mismatch")
+}
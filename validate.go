@@ -0,0 +1,350 @@
+// Copyright 2023 The Go SSI Framework Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+package diddoc
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+)
+
+// Severity is the severity of a violation, an error breaks conformance with DID Core and a warning does not
+type Severity string
+
+const (
+	SeverityError   Severity = "error"
+	SeverityWarning Severity = "warning"
+)
+
+var (
+	errMissingProperty      error = errors.New("missing_property")
+	errInvalidContext       error = errors.New("invalid_context")
+	errInvalidURI           error = errors.New("invalid_uri")
+	errUnresolvedReference  error = errors.New("unresolved_reference")
+	errMissingPublicKey     error = errors.New("missing_public_key")
+	errUnreferencedMethod   error = errors.New("unreferenced_verification_method")
+	errMissingEndpoint      error = errors.New("missing_service_endpoint")
+	errInvalidPropertyValue error = errors.New("invalid_property_value")
+)
+
+// ValidationOptions configures the conformance checks of Validate
+type ValidationOptions struct {
+	// RequireContext reports a missing @context as an error, as required by the JSON-LD representation
+	RequireContext bool
+	// Strict reports the warnings as errors
+	Strict bool
+	// Resolver resolves the references to verification methods of other DIDs, which are otherwise reported
+	// as warnings as they can not be checked
+	Resolver Resolver
+	// Context is the context of the resolution, the background context by default
+	Context context.Context
+}
+
+// Violation is a violation of a DID Core rule, the pointer is the JSON pointer of the violating property
+type Violation struct {
+	Pointer  string
+	Severity Severity
+	Err      error
+}
+
+func (v Violation) Error() string {
+	return fmt.Sprintf("%s %s: %v", v.Severity, v.Pointer, v.Err)
+}
+
+func (v Violation) Unwrap() error {
+	return v.Err
+}
+
+// Violations is the result of Validate
+type Violations []Violation
+
+// Errors returns the violations with the error severity
+func (vs Violations) Errors() Violations {
+	return vs.filter(SeverityError)
+}
+
+// Warnings returns the violations with the warning severity
+func (vs Violations) Warnings() Violations {
+	return vs.filter(SeverityWarning)
+}
+
+// Valid reports whether the document conforms to DID Core, warnings are allowed
+func (vs Violations) Valid() bool {
+	return len(vs.Errors()) == 0
+}
+
+func (vs Violations) filter(severity Severity) Violations {
+	var filtered Violations
+	for _, v := range vs {
+		if v.Severity == severity {
+			filtered = append(filtered, v)
+		}
+	}
+	return filtered
+}
+
+// validator collects the violations of a document
+type validator struct {
+	opts       ValidationOptions
+	violations Violations
+}
+
+func (v *validator) error(pointer string, err error) {
+	v.violations = append(v.violations, Violation{Pointer: pointer, Severity: SeverityError, Err: err})
+}
+
+func (v *validator) warning(pointer string, err error) {
+	severity := SeverityWarning
+	if v.opts.Strict {
+		severity = SeverityError
+	}
+	v.violations = append(v.violations, Violation{Pointer: pointer, Severity: severity, Err: err})
+}
+
+// Validate checks the document against the rules of DID Core, and returns the violations. The id must be a DID,
+// the ids of the verification methods and services must be unique, the references of the verification
+// relationships must resolve to a verification method, the controllers must be DIDs, alsoKnownAs must be URIs,
+// and the @context must start with the DID Core context. A reference to a verification method of another DID is
+// a warning, unless the Resolver of the options is set to resolve it.
+func (d *Document) Validate(opts ValidationOptions) Violations {
+	v := &validator{opts: opts}
+	subject, external := d.validate(v)
+
+	// the lock is released before resolving, as the resolver may return or change this document
+	o := lookupOptions{ctx: opts.Context, resolver: opts.Resolver}
+	if o.ctx == nil {
+		o.ctx = context.Background()
+	}
+	controllers := map[string]*Document{}
+	for _, r := range external {
+		if _, err := d.resolveReference(r.id, subject, o, controllers); err != nil {
+			v.error(r.pointer, fmt.Errorf("%w: %v", errUnresolvedReference, err))
+		}
+	}
+	return v.violations
+}
+
+// reference is a reference of a verification relationship at the JSON pointer
+type reference struct {
+	pointer string
+	id      string
+}
+
+// validate checks the document, and returns the subject and the references to verification methods of other DIDs
+// that are to be resolved with the resolver of the options
+func (d *Document) validate(v *validator) (DID, []reference) {
+	defer d.rlock()()
+
+	subject := d.validateSubject(v)
+	d.validateContext(v)
+
+	var controllers []string
+	if err := encode(&controllers, d.get(controllerKey)); err != nil {
+		v.error(pointer(controllerKey), errInvalidPropertyValue)
+	}
+	for i, controller := range controllers {
+		if _, err := ParseDID(controller); err != nil {
+			v.error(d.pointer(controllerKey, i), err)
+		}
+	}
+	var alsoKnownAs []string
+	if err := encode(&alsoKnownAs, d.get(alsoKnownAsKey)); err != nil {
+		v.error(pointer(alsoKnownAsKey), errInvalidPropertyValue)
+	}
+	for i, uri := range alsoKnownAs {
+		if !isURI(uri) {
+			v.error(d.pointer(alsoKnownAsKey, i), errInvalidURI)
+		}
+	}
+
+	// the references are checked once the ids of all verification methods, including the embedded, are known
+	var references, external []reference
+	ids := map[string]bool{}
+	var verificationMethods []VerificationMethod
+	if err := encode(&verificationMethods, d.get(verificationMethodKey)); err != nil {
+		v.error(pointer(verificationMethodKey), errInvalidPropertyValue)
+	}
+	for i, verificationMethod := range verificationMethods {
+		validateVerificationMethod(v, d.pointer(verificationMethodKey, i), verificationMethod, subject, ids)
+	}
+	for _, key := range verificationRelationshipKeys {
+		var relations []VerificationRelation
+		if err := encode(&relations, d.get(key)); err != nil {
+			v.error(pointer(key), errInvalidPropertyValue)
+		}
+		for i, relation := range relations {
+			if value, ok := relation.(string); ok {
+				references = append(references, reference{pointer: d.pointer(key, i), id: normalizeDIDURL(value, subject)})
+				continue
+			}
+			var verificationMethod VerificationMethod
+			if err := encode(&verificationMethod, relation); err != nil {
+				v.error(d.pointer(key, i), err)
+				continue
+			}
+			validateVerificationMethod(v, d.pointer(key, i), verificationMethod, subject, ids)
+		}
+	}
+	referenced := map[string]bool{}
+	for _, r := range references {
+		referenced[r.id] = true
+		if ids[r.id] {
+			continue
+		}
+		// a reference to a verification method of another DID can only be checked by resolving that DID
+		u, err := ParseDIDURL(r.id)
+		switch {
+		case err != nil || u.IsRelative() || u.DID.String() == subject.String():
+			v.error(r.pointer, errUnresolvedReference)
+		case v.opts.Resolver == nil:
+			v.warning(r.pointer, errUnresolvedReference)
+		default:
+			external = append(external, r)
+		}
+	}
+	for i, verificationMethod := range verificationMethods {
+		if !referenced[normalizeDIDURL(verificationMethod.Id, subject)] {
+			v.warning(d.pointer(verificationMethodKey, i), errUnreferencedMethod)
+		}
+	}
+
+	var services []Service
+	if err := encode(&services, d.get(serviceKey)); err != nil {
+		v.error(pointer(serviceKey), errInvalidPropertyValue)
+	}
+	serviceIds := map[string]bool{}
+	for i, service := range services {
+		switch {
+		case service.Id == "":
+			v.error(d.pointer(serviceKey, i, subjectKey), errMissingProperty)
+		case !isURI(normalizeDIDURL(service.Id, subject)):
+			v.error(d.pointer(serviceKey, i, subjectKey), errInvalidURI)
+		case serviceIds[normalizeDIDURL(service.Id, subject)]:
+			v.error(d.pointer(serviceKey, i, subjectKey), errDuplicateId)
+		default:
+			serviceIds[normalizeDIDURL(service.Id, subject)] = true
+		}
+		if service.Type == "" {
+			v.error(d.pointer(serviceKey, i, "type"), errMissingProperty)
+		}
+		if service.ServiceEndpoint.IsZero() {
+			v.error(d.pointer(serviceKey, i, "serviceEndpoint"), errMissingEndpoint)
+		}
+	}
+	return subject, external
+}
+
+func (d *Document) validateSubject(v *validator) DID {
+	value := d.get(subjectKey)
+	if value == nil {
+		v.error(pointer(subjectKey), errMissingProperty)
+		return DID{}
+	}
+	var id string
+	if err := encode(&id, value); err != nil {
+		v.error(pointer(subjectKey), errInvalidPropertyValue)
+		return DID{}
+	}
+	subject, err := ParseDID(id)
+	if err != nil {
+		v.error(pointer(subjectKey), err)
+		return DID{}
+	}
+	return subject
+}
+
+func (d *Document) validateContext(v *validator) {
+	value := d.get(contextKey)
+	if value == nil {
+		if v.opts.RequireContext {
+			v.error(pointer(contextKey), errMissingProperty)
+		} else {
+			v.warning(pointer(contextKey), errMissingProperty)
+		}
+		return
+	}
+	// a context is a URI or an embedded context
+	contexts, _ := elements(value)
+	if len(contexts) == 0 {
+		v.error(pointer(contextKey), errInvalidContext)
+		return
+	}
+	for i, context := range contexts {
+		switch context.(type) {
+		case string, map[string]interface{}:
+		default:
+			v.error(d.pointer(contextKey, i), errInvalidContext)
+		}
+	}
+	if contexts[0] != ContextDIDv1 {
+		v.error(d.pointer(contextKey, 0), errInvalidContext)
+	}
+}
+
+// validateVerificationMethod checks the verification method, and records its id in the ids
+func validateVerificationMethod(v *validator, at string, verificationMethod VerificationMethod, subject DID, ids map[string]bool) {
+	id := normalizeDIDURL(verificationMethod.Id, subject)
+	switch {
+	case verificationMethod.Id == "":
+		v.error(at+pointer(subjectKey), errMissingProperty)
+	case !isDIDURL(id):
+		v.error(at+pointer(subjectKey), errInvalidDIDURL)
+	case ids[id]:
+		v.error(at+pointer(subjectKey), errDuplicateId)
+	default:
+		ids[id] = true
+	}
+	if verificationMethod.Type == "" {
+		v.error(at+pointer("type"), errMissingProperty)
+	}
+	if verificationMethod.Controller == "" {
+		v.error(at+pointer(controllerKey), errMissingProperty)
+	} else if _, err := ParseDID(verificationMethod.Controller); err != nil {
+		v.error(at+pointer(controllerKey), err)
+	}
+	if verificationMethod.PubicKeyJWK == nil && verificationMethod.PublicKeyMultibase == "" &&
+		verificationMethod.PublicKeyBase58 == "" && verificationMethod.PublicKeyHex == "" &&
+		verificationMethod.PublicKeyPem == "" && verificationMethod.BlockchainAccountId == "" {
+		v.warning(at, errMissingPublicKey)
+	}
+}
+
+// isDIDURL reports whether the string is an absolute DID URL
+func isDIDURL(s string) bool {
+	u, err := ParseDIDURL(s)
+	return err == nil && !u.IsRelative()
+}
+
+// isURI reports whether the string is an absolute URI as defined in RFC 3986
+func isURI(s string) bool {
+	u, err := url.Parse(s)
+	return err == nil && u.Scheme != ""
+}
+
+// pointer returns the JSON pointer of the element i of the property, followed by the tokens. The index is
+// omitted when the property was a single value, which the builder turned into a set.
+func (d *Document) pointer(key string, i int, tokens ...interface{}) string {
+	if _, isSet := elements(d.get(key)); !isSet || d.scalars[key] {
+		return pointer(key) + pointer(tokens...)
+	}
+	return pointer(key, i) + pointer(tokens...)
+}
+
+// pointer returns the JSON pointer (RFC 6901) of the tokens, which are member names or array indices
+func pointer(tokens ...interface{}) string {
+	var b strings.Builder
+	for _, token := range tokens {
+		b.WriteByte('/')
+		switch t := token.(type) {
+		case int:
+			b.WriteString(strconv.Itoa(t))
+		case string:
+			b.WriteString(strings.NewReplacer("~", "~0", "/", "~1").Replace(t))
+		}
+	}
+	return b.String()
+}
@@ -0,0 +1,132 @@
+// Copyright 2023 The Go SSI Framework Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+package diddoc_test
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/gossif/diddoc"
+	"github.com/gossif/diddoc/didkey"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestValidate(t *testing.T) {
+	verificationMethod := diddoc.VerificationMethod{Id: "did:example:123#key-1", Type: diddoc.MultikeyType, Controller: "did:example:123", PublicKeyMultibase: "z6MkhaXgBZDvotDkL5257faiztiGiC2QtKLGpbnnEGta2doK"}
+
+	t.Run("valid", func(t *testing.T) {
+		doc, err := diddoc.NewBuilder().
+			Context(diddoc.ContextDIDv1).
+			Subject("did:example:123").
+			Controller("did:example:123").
+			AlsoKnownAs("https://example.com/alice").
+			VerificationMethod(verificationMethod).
+			Authentication("#key-1").
+			Build()
+		require.NoError(t, err)
+
+		violations := doc.Validate(diddoc.ValidationOptions{})
+		assert.Empty(t, violations)
+		assert.True(t, violations.Valid())
+	})
+	t.Run("violations", func(t *testing.T) {
+		doc := diddoc.NewDocument()
+		require.NoError(t, doc.Set("@context", []string{"https://w3id.org/security/multikey/v1", diddoc.ContextDIDv1}))
+		require.NoError(t, doc.Set("id", "did:example:123"))
+		require.NoError(t, doc.Set("alsoKnownAs", []string{"alice"}))
+		require.NoError(t, doc.Set("verificationMethod", []diddoc.VerificationMethod{verificationMethod, verificationMethod, {Id: "#key-2", Type: diddoc.MultikeyType, Controller: "did:example:123"}}))
+		require.NoError(t, doc.Set("authentication", []diddoc.VerificationRelation{"#key-1", "#key-3"}))
+		require.NoError(t, doc.Set("service", []diddoc.Service{{Id: "#linked-domain", Type: diddoc.LinkedDomainsType}}))
+
+		violations := doc.Validate(diddoc.ValidationOptions{})
+		assert.False(t, violations.Valid())
+
+		var errors, warnings []string
+		for _, v := range violations.Errors() {
+			errors = append(errors, v.Pointer+" "+v.Err.Error())
+		}
+		for _, v := range violations.Warnings() {
+			warnings = append(warnings, v.Pointer+" "+v.Err.Error())
+		}
+		assert.Equal(t, []string{
+			"/@context/0 invalid_context",
+			"/alsoKnownAs/0 invalid_uri",
+			"/verificationMethod/1/id duplicate_id",
+			"/authentication/1 unresolved_reference",
+			"/service/0/serviceEndpoint missing_service_endpoint",
+		}, errors)
+		assert.Equal(t, []string{
+			"/verificationMethod/2 missing_public_key",
+			"/verificationMethod/2 unreferenced_verification_method",
+		}, warnings)
+	})
+	t.Run("single values", func(t *testing.T) {
+		var doc diddoc.Document
+		require.NoError(t, json.Unmarshal([]byte(`{
+			"@context": "https://w3id.org/security/multikey/v1",
+			"id": "did:example:123",
+			"alsoKnownAs": "alice",
+			"service": {"id": "#linked-domain", "type": "LinkedDomains"}
+		}`), &doc))
+
+		var errors []string
+		for _, v := range doc.Validate(diddoc.ValidationOptions{}).Errors() {
+			errors = append(errors, v.Pointer+" "+v.Err.Error())
+		}
+		assert.Equal(t, []string{
+			"/@context invalid_context",
+			"/alsoKnownAs invalid_uri",
+			"/service/serviceEndpoint missing_service_endpoint",
+		}, errors)
+	})
+	t.Run("reference of another DID", func(t *testing.T) {
+		key := "did:key:z6MkhaXgBZDvotDkL5257faiztiGiC2QtKLGpbnnEGta2doK#z6MkhaXgBZDvotDkL5257faiztiGiC2QtKLGpbnnEGta2doK"
+		doc, err := diddoc.NewBuilder().
+			Context(diddoc.ContextDIDv1).
+			Subject("did:example:123").
+			CapabilityInvocation([]diddoc.VerificationRelation{key, "did:key:z6MkhaXgBZDvotDkL5257faiztiGiC2QtKLGpbnnEGta2doK#key-2"}).
+			Build()
+		require.NoError(t, err)
+
+		violations := doc.Validate(diddoc.ValidationOptions{})
+		assert.True(t, violations.Valid())
+		require.Len(t, violations, 2)
+		assert.Equal(t, "warning /capabilityInvocation/0: unresolved_reference", violations[0].Error())
+
+		violations = doc.Validate(diddoc.ValidationOptions{Resolver: didkey.NewResolver()})
+		require.Len(t, violations, 1)
+		assert.Equal(t, "/capabilityInvocation/1", violations[0].Pointer)
+		assert.Equal(t, diddoc.SeverityError, violations[0].Severity)
+		assert.ErrorContains(t, violations[0], "unresolved_reference")
+
+		// the resolver may change the document that is validated
+		resolver := diddoc.ResolverFunc(func(ctx context.Context, did string, opts diddoc.ResolutionOptions) (*diddoc.Document, diddoc.ResolutionMetadata, diddoc.DocumentMetadata, error) {
+			if err := doc.Set("alsoKnownAs", []string{"https://example.com/alice"}); err != nil {
+				return nil, diddoc.ResolutionMetadata{}, diddoc.DocumentMetadata{}, err
+			}
+			return didkey.NewResolver().Resolve(ctx, did, opts)
+		})
+		assert.Len(t, doc.Validate(diddoc.ValidationOptions{Resolver: resolver}), 1)
+	})
+	t.Run("missing id and context", func(t *testing.T) {
+		doc := diddoc.NewDocument()
+
+		violations := doc.Validate(diddoc.ValidationOptions{})
+		require.Len(t, violations, 2)
+		assert.Equal(t, "error /id: missing_property", violations[0].Error())
+		assert.Equal(t, "warning /@context: missing_property", violations[1].Error())
+
+		violations = doc.Validate(diddoc.ValidationOptions{RequireContext: true})
+		assert.Len(t, violations.Errors(), 2)
+	})
+	t.Run("strict", func(t *testing.T) {
+		doc, err := diddoc.NewBuilder().Context(diddoc.ContextDIDv1).Subject("did:example:123").VerificationMethod(verificationMethod).Build()
+		require.NoError(t, err)
+
+		assert.True(t, doc.Validate(diddoc.ValidationOptions{}).Valid())
+		assert.False(t, doc.Validate(diddoc.ValidationOptions{Strict: true}).Valid())
+	})
+}
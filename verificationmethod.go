@@ -0,0 +1,175 @@
+// Copyright 2023 The Go SSI Framework Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+package diddoc
+
+import (
+	"crypto"
+	"crypto/x509"
+	"encoding/hex"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/gossif/diddoc/internal/jwkutil"
+	"github.com/gossif/diddoc/multikey"
+	"github.com/lestrrat-go/jwx/v2/jwk"
+)
+
+const publicKeyJwkKey string = "publicKeyJwk"
+
+var (
+	errPublicKeyNotFound    error = errors.New("public_key_not_found")
+	errPrivateKeyNotAllowed error = errors.New("private_key_not_allowed")
+	errInvalidPublicKeyJWK  error = errors.New("invalid_public_key_jwk")
+	errInvalidPublicKeyPem  error = errors.New("invalid_public_key_pem")
+	errInvalidPublicKeyHex  error = errors.New("invalid_public_key_hex")
+
+	errUnsupportedVerificationMethodType error = errors.New("unsupported_verification_method_type")
+)
+
+// PublicKey returns the public key of the verification method, from the publicKeyMultibase, the publicKeyJwk,
+// or the legacy publicKeyBase58, publicKeyHex and publicKeyPem.
+// Ed25519 keys are an ed25519.PublicKey, X25519 keys a x25519.PublicKey, EC keys an *ecdsa.PublicKey, RSA keys
+// an *rsa.PublicKey and BLS12-381 G2 keys a multikey.BLS12381G2PublicKey.
+func (v VerificationMethod) PublicKey() (crypto.PublicKey, error) {
+	switch {
+	case v.PublicKeyMultibase != "":
+		return multikey.DecodePublicKey(v.PublicKeyMultibase)
+	case v.PubicKeyJWK != nil:
+		if jwkutil.IsPrivate(v.PubicKeyJWK) {
+			return nil, errPrivateKeyNotAllowed
+		}
+		return jwkutil.PublicKey(v.PubicKeyJWK)
+	case v.PublicKeyBase58 != "":
+		data, err := multikey.DecodeBase58(v.PublicKeyBase58)
+		if err != nil {
+			return nil, err
+		}
+		return v.rawPublicKey(data)
+	case v.PublicKeyHex != "":
+		data, err := hex.DecodeString(strings.TrimPrefix(v.PublicKeyHex, "0x"))
+		if err != nil {
+			return nil, errInvalidPublicKeyHex
+		}
+		return v.rawPublicKey(data)
+	case v.PublicKeyPem != "":
+		return parsePublicKeyPem(v.PublicKeyPem)
+	}
+	// a blockchainAccountId identifies the key by its account, it holds no key material
+	return nil, errPublicKeyNotFound
+}
+
+// rawPublicKey converts the raw key bytes of a publicKeyBase58 or publicKeyHex into a public key,
+// the key type is derived from the verification method type
+func (v VerificationMethod) rawPublicKey(data []byte) (crypto.PublicKey, error) {
+	var codec multikey.Codec
+	switch v.Type {
+	case Ed25519VerificationKey2018Type, Ed25519VerificationKey2020Type:
+		codec = multikey.Ed25519Pub
+	case X25519KeyAgreementKey2019Type, X25519KeyAgreementKey2020Type:
+		codec = multikey.X25519Pub
+	case EcdsaSecp256k1VerificationKey2019Type:
+		codec = multikey.Secp256k1Pub
+	case EcdsaSecp256r1VerificationKey2019Type:
+		codec = multikey.P256Pub
+	case Bls12381G2Key2020Type:
+		codec = multikey.BLS12381G2Pub
+	default:
+		return nil, fmt.Errorf("%w: %q", errUnsupportedVerificationMethodType, v.Type)
+	}
+	return multikey.UnmarshalPublicKey(codec, data)
+}
+
+// parsePublicKeyPem parses a PEM encoded PKIX public key, a published document must not contain private key material
+func parsePublicKeyPem(s string) (crypto.PublicKey, error) {
+	block, _ := pem.Decode([]byte(s))
+	if block == nil {
+		return nil, errInvalidPublicKeyPem
+	}
+	if strings.Contains(block.Type, "PRIVATE") {
+		return nil, errPrivateKeyNotAllowed
+	}
+	key, err := x509.ParsePKIXPublicKey(block.Bytes)
+	if err != nil {
+		return nil, errInvalidPublicKeyPem
+	}
+	return key, nil
+}
+
+// JWK returns the public key of the verification method as a JWK, the publicKeyMultibase is
+// converted when the verification method has no publicKeyJwk
+func (v VerificationMethod) JWK() (jwk.Key, error) {
+	if v.PubicKeyJWK != nil {
+		if jwkutil.IsPrivate(v.PubicKeyJWK) {
+			return nil, errPrivateKeyNotAllowed
+		}
+		return v.PubicKeyJWK, nil
+	}
+	key, err := v.PublicKey()
+	if err != nil {
+		return nil, err
+	}
+	return jwkutil.FromPublicKey(key)
+}
+
+func (v *VerificationMethod) UnmarshalJSON(data []byte) error {
+	type verificationMethod VerificationMethod
+	var raw struct {
+		verificationMethod
+		PubicKeyJWK json.RawMessage `json:"publicKeyJwk,omitempty"`
+	}
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return err
+	}
+	method := VerificationMethod(raw.verificationMethod)
+	if len(raw.PubicKeyJWK) > 0 && string(raw.PubicKeyJWK) != "null" {
+		key, err := parsePublicKeyJWK(raw.PubicKeyJWK)
+		if err != nil {
+			return propertyError(publicKeyJwkKey, err)
+		}
+		method.PubicKeyJWK = key
+	}
+	*v = method
+	return nil
+}
+
+// decode implements the decoder of the encoder, a map is decoded through its JSON representation,
+// so that the publicKeyJwk is always a typed jwk.Key
+func (v *VerificationMethod) decode(src interface{}) error {
+	switch value := src.(type) {
+	case VerificationMethod:
+		if value.PubicKeyJWK != nil && jwkutil.IsPrivate(value.PubicKeyJWK) {
+			return propertyError(publicKeyJwkKey, errPrivateKeyNotAllowed)
+		}
+		*v = value
+		return nil
+	case *VerificationMethod:
+		return v.decode(*value)
+	case nil, string, bool, float64, json.Number:
+		return errUnsupportedSourceType
+	}
+	// maps and other types are decoded through their JSON representation
+	data, err := json.Marshal(src)
+	if err != nil {
+		return errUnsupportedSourceType
+	}
+	if len(data) == 0 || data[0] != '{' {
+		return errUnsupportedSourceType
+	}
+	return v.UnmarshalJSON(data)
+}
+
+// parsePublicKeyJWK parses the publicKeyJwk, a published document must not contain private key material
+func parsePublicKeyJWK(data []byte) (jwk.Key, error) {
+	key, err := jwk.ParseKey(data)
+	if err != nil {
+		return nil, errInvalidPublicKeyJWK
+	}
+	if jwkutil.IsPrivate(key) {
+		return nil, errPrivateKeyNotAllowed
+	}
+	return key, nil
+}
@@ -0,0 +1,115 @@
+// Copyright 2023 The Go SSI Framework Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+package diddoc_test
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"encoding/hex"
+	"encoding/json"
+	"encoding/pem"
+	"testing"
+
+	"github.com/decred/dcrd/dcrec/secp256k1/v4"
+	"github.com/gossif/diddoc"
+	"github.com/gossif/diddoc/multikey"
+	"github.com/lestrrat-go/jwx/v2/jwk"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestVerificationMethodPublicKey(t *testing.T) {
+	edKey, _, _ := ed25519.GenerateKey(rand.Reader)
+	p256Key, _ := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+
+	t.Run("multibase", func(t *testing.T) {
+		encoded, err := multikey.EncodePublicKeyWith(multikey.Base64URL, edKey)
+		require.NoError(t, err)
+
+		key, err := diddoc.VerificationMethod{PublicKeyMultibase: encoded}.PublicKey()
+		require.NoError(t, err)
+		assert.Equal(t, edKey, key)
+	})
+	t.Run("jwk", func(t *testing.T) {
+		jwkKey, err := jwk.FromRaw(&p256Key.PublicKey)
+		require.NoError(t, err)
+
+		key, err := diddoc.VerificationMethod{PubicKeyJWK: jwkKey}.PublicKey()
+		require.NoError(t, err)
+		assert.True(t, p256Key.PublicKey.Equal(key))
+	})
+	t.Run("not found", func(t *testing.T) {
+		_, err := diddoc.VerificationMethod{Id: "did:example:123#key-1"}.PublicKey()
+		assert.ErrorContains(t, err, "public_key_not_found")
+	})
+}
+
+func TestVerificationMethodJWK(t *testing.T) {
+	edKey, _, _ := ed25519.GenerateKey(rand.Reader)
+
+	t.Run("from multibase", func(t *testing.T) {
+		encoded, err := multikey.EncodePublicKey(edKey)
+		require.NoError(t, err)
+
+		key, err := diddoc.VerificationMethod{PublicKeyMultibase: encoded}.JWK()
+		require.NoError(t, err)
+		assert.Equal(t, "OKP", key.KeyType().String())
+	})
+	t.Run("private key in document", func(t *testing.T) {
+		inputBytes := []byte(`{"id":"did:example:123","verificationMethod":[{"id":"did:example:123#key-1","type":"JsonWebKey2020","controller":"did:example:123","publicKeyJwk":{"kty":"OKP","crv":"Ed25519","x":"VCpo2LMLhn6iWku8MKvSLg2ZAoC-nlOyPVQaO3FxVeQ","d":"nWGxne_9WmC6hEr0kuwsxERJxWl7MmkZcDusAxyuf2A"}}]}`)
+
+		doc := diddoc.NewDocument()
+		assert.ErrorContains(t, json.Unmarshal(inputBytes, doc), "private_key_not_allowed")
+	})
+}
+
+func TestLegacyVerificationMethod(t *testing.T) {
+	edKey, _, _ := ed25519.GenerateKey(rand.Reader)
+	p256Key, _ := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	pkix, _ := x509.MarshalPKIXPublicKey(&p256Key.PublicKey)
+	k256Key, _ := secp256k1.GeneratePrivateKey()
+
+	type errorTestCases struct {
+		description        string
+		verificationMethod diddoc.VerificationMethod
+		expectedKey        crypto.PublicKey
+		expectedError      string
+	}
+	for _, scenario := range []errorTestCases{
+		{description: "publicKeyBase58", verificationMethod: diddoc.VerificationMethod{Type: diddoc.Ed25519VerificationKey2018Type, PublicKeyBase58: multikey.EncodeBase58(edKey)}, expectedKey: edKey},
+		{description: "publicKeyHex", verificationMethod: diddoc.VerificationMethod{Type: diddoc.EcdsaSecp256k1VerificationKey2019Type, PublicKeyHex: hex.EncodeToString(k256Key.PubKey().SerializeCompressed())}, expectedKey: k256Key.PubKey().ToECDSA()},
+		{description: "publicKeyPem", verificationMethod: diddoc.VerificationMethod{Type: "JsonWebKey2020", PublicKeyPem: string(pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: pkix}))}, expectedKey: &p256Key.PublicKey},
+		{description: "private publicKeyPem", verificationMethod: diddoc.VerificationMethod{PublicKeyPem: string(pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: pkix}))}, expectedError: "private_key_not_allowed"},
+		{description: "unsupported type", verificationMethod: diddoc.VerificationMethod{Type: "UnknownKey2023", PublicKeyBase58: multikey.EncodeBase58(edKey)}, expectedError: "unsupported_verification_method_type"},
+		{description: "blockchainAccountId", verificationMethod: diddoc.VerificationMethod{Type: "EcdsaSecp256k1RecoveryMethod2020", BlockchainAccountId: "eip155:1:0xb9c5714089478a327f09197987f16f9e5d936e8a"}, expectedError: "public_key_not_found"},
+	} {
+		t.Run(scenario.description, func(t *testing.T) {
+			key, err := scenario.verificationMethod.PublicKey()
+			if scenario.expectedError != "" {
+				assert.ErrorContains(t, err, scenario.expectedError)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, scenario.expectedKey, key)
+		})
+	}
+	t.Run("round trip", func(t *testing.T) {
+		inputBytes := []byte(`{"id":"did:example:123","verificationMethod":[{"id":"did:example:123#key-1","type":"Ed25519VerificationKey2018","controller":"did:example:123","publicKeyBase58":"H3C2AVvLMv6gmMNam3uVAjZpfkcJCwDwnZn6z3wXmqPV"},{"id":"did:example:123#key-2","type":"EcdsaSecp256k1VerificationKey2019","controller":"did:example:123","publicKeyHex":"02b97c30de767f084ce3080168ee293053ba33b235d7116a3263d29f1450936b71"}]}`)
+
+		doc := diddoc.NewDocument()
+		require.NoError(t, json.Unmarshal(inputBytes, doc))
+
+		verificationMethod, err := doc.GetVerificationMethodById("#key-1")
+		require.NoError(t, err)
+		assert.Equal(t, "H3C2AVvLMv6gmMNam3uVAjZpfkcJCwDwnZn6z3wXmqPV", verificationMethod.PublicKeyBase58)
+
+		actualBytes, err := json.Marshal(doc)
+		require.NoError(t, err)
+		assert.JSONEq(t, string(inputBytes), string(actualBytes))
+	})
+}